@@ -1,34 +1,31 @@
 package api
 
 import (
-	"compress/gzip"
 	"context"
 	"database/sql"
-	"encoding/xml"
 	"fmt"
-	"html"
-	"io"
 	"log"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/LFroesch/Gator/internal/database"
+	"github.com/LFroesch/Gator/internal/rss"
+	"github.com/LFroesch/Gator/internal/scraper"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"golang.org/x/text/encoding/charmap"
-	"golang.org/x/text/transform"
 )
 
 type Server struct {
-	db *database.Queries
+	db   *database.Queries
+	conn *sql.DB
 }
 
-func NewServer(db *database.Queries) *Server {
-	return &Server{db: db}
+// NewServer builds the API server on conn, whose queries are db
+func NewServer(conn *sql.DB, db *database.Queries) *Server {
+	return &Server{db: db, conn: conn}
 }
 
 func (s *Server) Start(port string) error {
@@ -57,7 +54,12 @@ func (s *Server) Start(port string) error {
 		// Feed routes
 		api.POST("/feeds", s.createFeed)
 		api.GET("/feeds", s.getAllFeeds)
+		api.GET("/feeds/broken", s.getBrokenFeeds)
 		api.PUT("/feeds/:feedId", s.updateFeed)
+		api.PUT("/feeds/:feedId/interval", s.setFeedInterval)
+		api.PUT("/feeds/:feedId/fulltext", s.setFeedFullText)
+		api.POST("/feeds/:feedId/enable", s.enableFeed)
+		api.GET("/feeds/:feedId/fetches", s.getFeedFetches)
 		api.DELETE("/feeds/:feedId", s.deleteFeed)
 
 		// Feed follow routes
@@ -67,12 +69,16 @@ func (s *Server) Start(port string) error {
 
 		// Post routes
 		api.GET("/posts/:userId", s.getUserPosts)
+		api.GET("/posts/:userId/:postId", s.getUserPost)
 
 		// Bookmark routes
 		api.POST("/bookmarks", s.createBookmark)
 		api.DELETE("/bookmarks/:userId/:postId", s.deleteBookmark)
 		api.GET("/bookmarks/:userId", s.getUserBookmarks)
 
+		// Post revision routes
+		api.GET("/revisions/:postId", s.getPostRevisions)
+
 		// Read status routes
 		api.POST("/reads", s.markPostRead)
 		api.DELETE("/reads/:userId/:postId", s.markPostUnread)
@@ -84,6 +90,10 @@ func (s *Server) Start(port string) error {
 		api.DELETE("/admin/posts", s.deleteAllPosts)
 	}
 
+	// Full text for posts fetched through the API is extracted in the
+	// background, so fetch requests return as soon as posts are stored
+	go scraper.RunFullTextWorker(context.Background(), s.db)
+
 	return r.Run(":" + port)
 }
 
@@ -189,7 +199,7 @@ func (s *Server) deleteUser(c *gin.Context) {
 // Feed handlers
 func (s *Server) createFeed(c *gin.Context) {
 	var req struct {
-		Name   string `json:"name" binding:"required"`
+		Name   string `json:"name"` // Defaults to the feed's own title
 		URL    string `json:"url" binding:"required"`
 		UserID string `json:"user_id" binding:"required"`
 	}
@@ -205,15 +215,33 @@ func (s *Server) createFeed(c *gin.Context) {
 		return
 	}
 
-	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      req.Name,
-		Url:       req.URL,
-		UserID:    userID,
-	})
+	// Website URLs are searched for feeds; when a site offers several the
+	// client gets them back to let the user pick one
+	candidates, err := rss.Discover(c.Request.Context(), req.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Couldn't fetch %s: %v", req.URL, err)})
+		return
+	}
+	if len(candidates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No RSS, Atom or JSON feed found at " + req.URL})
+		return
+	}
+	if len(candidates) > 1 {
+		c.JSON(http.StatusMultipleChoices, gin.H{
+			"error":      "This site offers several feeds, pick one",
+			"candidates": candidates,
+		})
+		return
+	}
 
+	// Make sure it really is a feed before storing it
+	parsed, err := rss.Probe(c.Request.Context(), candidates[0])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s is not a valid feed: %v", candidates[0].URL, err)})
+		return
+	}
+
+	feed, err := scraper.AddFeed(c.Request.Context(), s.db, userID, req.Name, candidates[0].URL, parsed)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -232,6 +260,69 @@ func (s *Server) getAllFeeds(c *gin.Context) {
 	c.JSON(http.StatusOK, feeds)
 }
 
+// getBrokenFeeds lists feeds that are failing or have been auto-disabled
+func (s *Server) getBrokenFeeds(c *gin.Context) {
+	feeds, err := s.db.GetBrokenFeeds(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if feeds == nil {
+		feeds = []database.Feed{}
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+// Feed re-enable handler
+func (s *Server) enableFeed(c *gin.Context) {
+	feedIDStr := c.Param("feedId")
+	feedID, err := uuid.Parse(feedIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+
+	feed, err := s.db.EnableFeed(context.Background(), feedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// getFeedFetches returns the most recent fetch history for a feed
+func (s *Server) getFeedFetches(c *gin.Context) {
+	feedIDStr := c.Param("feedId")
+	feedID, err := uuid.Parse(feedIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+
+	fetches, err := s.db.GetFeedFetches(c.Request.Context(), database.GetFeedFetchesParams{
+		FeedID: feedID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed history"})
+		return
+	}
+
+	if fetches == nil {
+		fetches = []database.FeedFetch{}
+	}
+
+	c.JSON(http.StatusOK, fetches)
+}
+
 // Feed update handler
 func (s *Server) updateFeed(c *gin.Context) {
 	feedIDStr := c.Param("feedId")
@@ -266,6 +357,75 @@ func (s *Server) updateFeed(c *gin.Context) {
 	c.JSON(http.StatusOK, feed)
 }
 
+// Feed refresh interval handler - a null or zero interval returns the feed to
+// automatic scheduling
+func (s *Server) setFeedInterval(c *gin.Context) {
+	feedIDStr := c.Param("feedId")
+	feedID, err := uuid.Parse(feedIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+
+	var req struct {
+		FetchIntervalSeconds *int32 `json:"fetch_interval_seconds"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interval := sql.NullInt32{}
+	if req.FetchIntervalSeconds != nil && *req.FetchIntervalSeconds > 0 {
+		if *req.FetchIntervalSeconds < 60 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Interval must be at least 60 seconds"})
+			return
+		}
+		interval = sql.NullInt32{Int32: *req.FetchIntervalSeconds, Valid: true}
+	}
+
+	feed, err := s.db.SetFeedFetchInterval(context.Background(), database.SetFeedFetchIntervalParams{
+		ID:                   feedID,
+		FetchIntervalSeconds: interval,
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// setFeedFullText turns full article extraction on or off for a feed
+func (s *Server) setFeedFullText(c *gin.Context) {
+	feedID, err := uuid.Parse(c.Param("feedId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+
+	var req struct {
+		FetchFullText bool `json:"fetch_full_text"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := s.db.SetFeedFullText(context.Background(), database.SetFeedFullTextParams{
+		ID:            feedID,
+		FetchFullText: req.FetchFullText,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
 // Feed delete handler
 func (s *Server) deleteFeed(c *gin.Context) {
 	feedIDStr := c.Param("feedId")
@@ -478,14 +638,22 @@ func (s *Server) getUserPosts(c *gin.Context) {
 			posts = make([]database.GetPostsForUserWithOffsetRow, len(postsOrig))
 			for i, post := range postsOrig {
 				posts[i] = database.GetPostsForUserWithOffsetRow{
-					ID:          post.ID,
-					CreatedAt:   post.CreatedAt,
-					UpdatedAt:   post.UpdatedAt,
-					Title:       post.Title,
-					Url:         post.Url,
-					Description: post.Description,
-					PublishedAt: post.PublishedAt,
-					FeedName:    post.FeedName,
+					ID:                   post.ID,
+					CreatedAt:            post.CreatedAt,
+					UpdatedAt:            post.UpdatedAt,
+					Title:                post.Title,
+					Url:                  post.Url,
+					Description:          post.Description,
+					PublishedAt:          post.PublishedAt,
+					FeedID:               post.FeedID,
+					Guid:                 post.Guid,
+					SourceUpdatedAt:      post.SourceUpdatedAt,
+					EditedAt:             post.EditedAt,
+					Author:               post.Author,
+					Content:              post.Content,
+					Summary:              post.Summary,
+					PublishedAtEstimated: post.PublishedAtEstimated,
+					FeedName:             post.FeedName,
 				}
 			}
 		}
@@ -496,15 +664,21 @@ func (s *Server) getUserPosts(c *gin.Context) {
 		return
 	}
 
-	// Enhance posts with bookmark and read status
+	// Enhance posts with bookmark and read status and any media enclosures
 	type EnhancedPost struct {
 		database.GetPostsForUserWithOffsetRow
-		IsBookmarked bool `json:"is_bookmarked"`
-		IsRead       bool `json:"is_read"`
+		IsBookmarked bool                     `json:"is_bookmarked"`
+		IsRead       bool                     `json:"is_read"`
+		HasContent   bool                     `json:"has_content"`
+		Enclosures   []database.PostEnclosure `json:"enclosures"`
 	}
 
 	enhancedPosts := make([]EnhancedPost, len(posts))
 	for i, post := range posts {
+		// Lists only carry the summary; the full body comes from getUserPost
+		hasContent := post.Content.Valid
+		post.Content = sql.NullString{}
+
 		// Check if bookmarked
 		isBookmarked, err := s.db.IsPostBookmarked(context.Background(), database.IsPostBookmarkedParams{
 			UserID: userID,
@@ -523,10 +697,17 @@ func (s *Server) getUserPosts(c *gin.Context) {
 			isRead = false // Default to false on error
 		}
 
+		enclosures, err := s.db.GetPostEnclosures(context.Background(), post.ID)
+		if err != nil || enclosures == nil {
+			enclosures = []database.PostEnclosure{}
+		}
+
 		enhancedPosts[i] = EnhancedPost{
 			GetPostsForUserWithOffsetRow: post,
 			IsBookmarked:                 isBookmarked,
 			IsRead:                       isRead,
+			HasContent:                   hasContent,
+			Enclosures:                   enclosures,
 		}
 	}
 
@@ -538,6 +719,78 @@ func (s *Server) getUserPosts(c *gin.Context) {
 	})
 }
 
+// getUserPost returns a single post with its full content, for a detail view
+func (s *Server) getUserPost(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	postID, err := uuid.Parse(c.Param("postId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	post, err := s.db.GetPostByID(c.Request.Context(), postID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post"})
+		return
+	}
+
+	isBookmarked, err := s.db.IsPostBookmarked(c.Request.Context(), database.IsPostBookmarkedParams{
+		UserID: userID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		isBookmarked = false
+	}
+	isRead, err := s.db.IsPostRead(c.Request.Context(), database.IsPostReadParams{
+		UserID: userID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		isRead = false
+	}
+	enclosures, err := s.db.GetPostEnclosures(c.Request.Context(), post.ID)
+	if err != nil || enclosures == nil {
+		enclosures = []database.PostEnclosure{}
+	}
+
+	c.JSON(http.StatusOK, struct {
+		database.GetPostByIDRow
+		IsBookmarked bool                     `json:"is_bookmarked"`
+		IsRead       bool                     `json:"is_read"`
+		Enclosures   []database.PostEnclosure `json:"enclosures"`
+	}{post, isBookmarked, isRead, enclosures})
+}
+
+// getPostRevisions returns the earlier versions of an edited post, newest first
+func (s *Server) getPostRevisions(c *gin.Context) {
+	postIDStr := c.Param("postId")
+	postID, err := uuid.Parse(postIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	revisions, err := s.db.GetPostRevisions(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+
+	if revisions == nil {
+		revisions = []database.PostRevision{}
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
 // Bookmark handlers
 func (s *Server) createBookmark(c *gin.Context) {
 	var req struct {
@@ -729,46 +982,6 @@ func (s *Server) markPostUnread(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Post marked as unread successfully"})
 }
 
-// RSS Feed structures for parsing
-type RSSFeed struct {
-	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
-	} `xml:"channel"`
-}
-
-type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
-}
-
-// AtomFeed represents an Atom feed structure (used by Reddit, YouTube, etc.)
-type AtomFeed struct {
-	Title       string      `xml:"title"`
-	Link        []AtomLink  `xml:"link"`
-	Description string      `xml:"subtitle"`
-	Entry       []AtomEntry `xml:"entry"`
-}
-
-type AtomLink struct {
-	Href string `xml:"href,attr"`
-	Rel  string `xml:"rel,attr"`
-}
-
-type AtomEntry struct {
-	Title            string     `xml:"title"`
-	Link             []AtomLink `xml:"link"`
-	Content          string     `xml:"content"`
-	Summary          string     `xml:"summary"`
-	Published        string     `xml:"published"`
-	Updated          string     `xml:"updated"`
-	MediaDescription string     `xml:"http://search.yahoo.com/mrss/ description"`
-}
-
 // fetchUserFeeds - API endpoint to fetch new posts for a user's followed feeds
 func (s *Server) fetchUserFeeds(c *gin.Context) {
 	userIDStr := c.Param("userId")
@@ -806,6 +1019,17 @@ func (s *Server) fetchUserFeeds(c *gin.Context) {
 			continue
 		}
 
+		// Disabled feeds stay off until someone re-enables them
+		if feed.DisabledAt.Valid {
+			continue
+		}
+
+		// A failing feed waits out its backoff or the host's Retry-After,
+		// however often the page asks for fresh posts
+		if feed.ConsecutiveFailures > 0 && feed.NextFetchAt.Valid && feed.NextFetchAt.Time.After(time.Now()) {
+			continue
+		}
+
 		// Fetch new posts from this feed
 		newPosts, err := s.scrapeFeedForAPI(feed)
 		if err != nil {
@@ -830,314 +1054,15 @@ func (s *Server) fetchUserFeeds(c *gin.Context) {
 
 // scrapeFeedForAPI - Internal function to scrape a feed and return count of new posts
 func (s *Server) scrapeFeedForAPI(feed database.Feed) (int, error) {
-	feedData, err := s.fetchRSSFeed(context.Background(), feed.Url)
+	newPosts, err := scraper.ScrapeFeed(context.Background(), s.conn, s.db, feed)
 	if err != nil {
-		return 0, fmt.Errorf("couldn't fetch feed %s: %w", feed.Name, err)
-	}
-
-	newPosts := 0
-	for _, item := range feedData.Channel.Item {
-		publishedAt := sql.NullTime{}
-
-		// Try parsing different date formats
-		if item.PubDate != "" {
-			// Try RFC1123Z first (RSS format)
-			if t, err := time.Parse(time.RFC1123Z, item.PubDate); err == nil {
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			} else if t, err := time.Parse(time.RFC1123, item.PubDate); err == nil {
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			} else if t, err := time.Parse(time.RFC3339, item.PubDate); err == nil {
-				// Atom format (ISO 8601)
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			} else if t, err := time.Parse("2006-01-02T15:04:05Z", item.PubDate); err == nil {
-				// Alternative ISO format
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			}
-		}
-
-		_, err = s.db.CreatePost(context.Background(), database.CreatePostParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now().UTC(),
-			UpdatedAt: time.Now().UTC(),
-			FeedID:    feed.ID,
-			Title:     item.Title,
-			Description: sql.NullString{
-				String: item.Description,
-				Valid:  true,
-			},
-			Url:         item.Link,
-			PublishedAt: publishedAt,
-		})
-		if err != nil {
-			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-				continue // Post already exists, skip
-			}
-			log.Printf("Couldn't create post: %v", err)
-			continue
-		}
-		newPosts++
+		return 0, err
 	}
 
 	log.Printf("Feed %s scraped, %d new posts added", feed.Name, newPosts)
 	return newPosts, nil
 }
 
-// fetchRSSFeed - Fetch and parse RSS feed
-func (s *Server) fetchRSSFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	request, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// Set headers that make the request appear more legitimate to avoid being blocked
-	request.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Gator RSS Reader/1.0; +https://github.com/user/gator)")
-	request.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml, */*")
-	request.Header.Set("Accept-Language", "en-US,en;q=0.9")
-	request.Header.Set("Accept-Encoding", "gzip, deflate")
-	request.Header.Set("Cache-Control", "no-cache")
-	request.Header.Set("Connection", "keep-alive")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-
-	// Handle gzipped responses
-	var reader io.Reader = response.Body
-	if response.Header.Get("Content-Encoding") == "gzip" {
-		gzipReader, err := gzip.NewReader(response.Body)
-		if err != nil {
-			return nil, err
-		}
-		defer gzipReader.Close()
-		reader = gzipReader
-	}
-
-	body, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, err
-	}
-
-	// Handle different character encodings
-	bodyStr := string(body)
-	if strings.Contains(bodyStr, `encoding="ISO-8859-1"`) {
-		decoder := charmap.ISO8859_1.NewDecoder()
-		utf8Body, err := io.ReadAll(transform.NewReader(strings.NewReader(bodyStr), decoder))
-		if err != nil {
-			log.Printf("Warning: Failed to convert from ISO-8859-1: %v", err)
-		} else {
-			// Fix the encoding declaration in the XML
-			bodyStr = string(utf8Body)
-			bodyStr = strings.Replace(bodyStr, `encoding="ISO-8859-1"`, `encoding="UTF-8"`, 1)
-			body = []byte(bodyStr)
-		}
-	}
-
-	// Clean up common XML issues before parsing
-	bodyStr = string(body)
-	bodyStr = s.cleanXML(bodyStr)
-
-	// Parse the XML - try RSS first, then Atom
-	var feed RSSFeed
-	err = xml.Unmarshal([]byte(bodyStr), &feed)
-	if err != nil || feed.Channel.Title == "" {
-		// If RSS parsing failed or didn't find channel, try Atom format
-		var atomFeed AtomFeed
-		err = xml.Unmarshal([]byte(bodyStr), &atomFeed)
-		if err != nil {
-			return nil, fmt.Errorf("XML parsing error: %w", err)
-		}
-
-		// Convert Atom to RSS format
-		feed.Channel.Title = atomFeed.Title
-		feed.Channel.Description = atomFeed.Description
-
-		// Find the alternate link
-		for _, link := range atomFeed.Link {
-			if link.Rel == "alternate" || link.Rel == "" {
-				feed.Channel.Link = link.Href
-				break
-			}
-		}
-
-		// Convert entries to items
-		feed.Channel.Item = make([]RSSItem, len(atomFeed.Entry))
-		for i, entry := range atomFeed.Entry {
-			feed.Channel.Item[i].Title = entry.Title
-
-			// Find the entry link
-			for _, link := range entry.Link {
-				if link.Rel == "alternate" || link.Rel == "" {
-					feed.Channel.Item[i].Link = link.Href
-					break
-				}
-			}
-
-			// Use content, summary, or media description for description
-			if entry.Content != "" {
-				feed.Channel.Item[i].Description = entry.Content
-			} else if entry.Summary != "" {
-				feed.Channel.Item[i].Description = entry.Summary
-			} else if entry.MediaDescription != "" {
-				feed.Channel.Item[i].Description = entry.MediaDescription
-			}
-
-			// Use published or updated for date
-			if entry.Published != "" {
-				feed.Channel.Item[i].PubDate = entry.Published
-			} else {
-				feed.Channel.Item[i].PubDate = entry.Updated
-			}
-		}
-	}
-
-	// Unescape HTML entities and clean up descriptions
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
-	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
-	for i := range feed.Channel.Item {
-		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
-		feed.Channel.Item[i].Description = s.cleanDescription(html.UnescapeString(feed.Channel.Item[i].Description))
-	}
-
-	return &feed, nil
-}
-
-// cleanDescription removes HTML tags and cleans up RSS feed descriptions
-func (s *Server) cleanDescription(description string) string {
-	// Special handling for Hacker News style feeds
-	if strings.Contains(description, "Article URL:") && strings.Contains(description, "Comments URL:") {
-		return s.extractHackerNewsDescription(description)
-	}
-
-	// First, unescape HTML entities so we can properly match HTML tags
-	unescaped := html.UnescapeString(description)
-
-	// Remove HTML comments
-	commentRegex := regexp.MustCompile(`<!--[\s\S]*?-->`)
-	unescaped = commentRegex.ReplaceAllString(unescaped, "")
-
-	// Remove Reddit-specific markers
-	unescaped = strings.ReplaceAll(unescaped, "<!-- SC_OFF -->", "")
-	unescaped = strings.ReplaceAll(unescaped, "<!-- SC_ON -->", "")
-
-	// Remove HTML tags - more comprehensive regex
-	htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
-	cleaned := htmlTagRegex.ReplaceAllString(unescaped, "")
-
-	// Decode common HTML entities that might remain
-	cleaned = strings.ReplaceAll(cleaned, "&amp;", "&")
-	cleaned = strings.ReplaceAll(cleaned, "&lt;", "<")
-	cleaned = strings.ReplaceAll(cleaned, "&gt;", ">")
-	cleaned = strings.ReplaceAll(cleaned, "&quot;", "\"")
-	cleaned = strings.ReplaceAll(cleaned, "&#39;", "'")
-	cleaned = strings.ReplaceAll(cleaned, "&nbsp;", " ")
-
-	// Remove extra whitespace and newlines
-	cleaned = strings.TrimSpace(cleaned)
-	cleaned = regexp.MustCompile(`\s+`).ReplaceAllString(cleaned, " ")
-
-	// Remove common RSS feed artifacts
-	cleaned = strings.ReplaceAll(cleaned, "\n", " ")
-	cleaned = strings.ReplaceAll(cleaned, "\r", " ")
-	cleaned = strings.ReplaceAll(cleaned, "\t", " ")
-
-	// Remove Reddit submission line (submitted by /u/username)
-	submittedRegex := regexp.MustCompile(`\s*submitted by\s+/u/\w+\s*`)
-	cleaned = submittedRegex.ReplaceAllString(cleaned, "")
-
-	// Remove [link] and [comments] markers at the end
-	linkCommentsRegex := regexp.MustCompile(`\s*\[link\]\s*\[comments\]\s*$`)
-	cleaned = linkCommentsRegex.ReplaceAllString(cleaned, "")
-
-	// Clean up any remaining extra spaces
-	cleaned = regexp.MustCompile(`\s+`).ReplaceAllString(cleaned, " ")
-	cleaned = strings.TrimSpace(cleaned)
-
-	return cleaned
-}
-
-// cleanXML fixes common XML parsing issues in RSS feeds
-func (s *Server) cleanXML(xmlContent string) string {
-	// Fix unclosed hr tags and similar issues
-	xmlContent = regexp.MustCompile(`<hr[^>]*>`).ReplaceAllString(xmlContent, "<hr/>")
-	xmlContent = regexp.MustCompile(`<br[^>]*>`).ReplaceAllString(xmlContent, "<br/>")
-	xmlContent = regexp.MustCompile(`<img([^>]*)>`).ReplaceAllString(xmlContent, "<img$1/>")
-	xmlContent = regexp.MustCompile(`<input([^>]*)>`).ReplaceAllString(xmlContent, "<input$1/>")
-
-	// Remove or fix other common problematic elements
-	xmlContent = strings.ReplaceAll(xmlContent, "&", "&amp;")
-	xmlContent = strings.ReplaceAll(xmlContent, "&amp;amp;", "&amp;")
-	xmlContent = strings.ReplaceAll(xmlContent, "&amp;lt;", "&lt;")
-	xmlContent = strings.ReplaceAll(xmlContent, "&amp;gt;", "&gt;")
-	xmlContent = strings.ReplaceAll(xmlContent, "&amp;quot;", "&quot;")
-
-	return xmlContent
-}
-
-// extractHackerNewsDescription extracts meaningful content from Hacker News style descriptions
-func (s *Server) extractHackerNewsDescription(description string) string {
-	// For Hacker News style feeds, we'll create a more meaningful description
-	// Extract the title from the link if possible, or provide a summary
-
-	// Remove HTML tags first
-	htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
-	plainText := htmlTagRegex.ReplaceAllString(description, "")
-
-	// Extract article URL
-	articleURLRegex := regexp.MustCompile(`Article URL:\s*([^\s]+)`)
-	matches := articleURLRegex.FindStringSubmatch(plainText)
-
-	var result string
-	if len(matches) > 1 {
-		articleURL := matches[1]
-		// Try to extract a meaningful description from the URL
-		if strings.Contains(articleURL, "github.com") {
-			result = "GitHub repository: " + s.extractGitHubRepoInfo(articleURL)
-		} else if strings.Contains(articleURL, "arxiv.org") {
-			result = "Research paper from arXiv"
-		} else if strings.Contains(articleURL, "youtube.com") || strings.Contains(articleURL, "youtu.be") {
-			result = "YouTube video"
-		} else {
-			// Extract domain for a generic description
-			domainRegex := regexp.MustCompile(`https?://([^/]+)`)
-			domainMatches := domainRegex.FindStringSubmatch(articleURL)
-			if len(domainMatches) > 1 {
-				domain := domainMatches[1]
-				result = fmt.Sprintf("Article from %s", domain)
-			} else {
-				result = "External article"
-			}
-		}
-	} else {
-		result = "Hacker News discussion"
-	}
-
-	// Add points and comments info if available
-	pointsRegex := regexp.MustCompile(`Points:\s*(\d+)`)
-	commentsRegex := regexp.MustCompile(`#\s*Comments:\s*(\d+)`)
-
-	pointsMatches := pointsRegex.FindStringSubmatch(plainText)
-	commentsMatches := commentsRegex.FindStringSubmatch(plainText)
-
-	if len(pointsMatches) > 1 && len(commentsMatches) > 1 {
-		result += fmt.Sprintf(" • %s points, %s comments", pointsMatches[1], commentsMatches[1])
-	}
-
-	return result
-}
-
-// extractGitHubRepoInfo extracts repository name from GitHub URL
-func (s *Server) extractGitHubRepoInfo(url string) string {
-	repoRegex := regexp.MustCompile(`github\.com/([^/]+)/([^/?]+)`)
-	matches := repoRegex.FindStringSubmatch(url)
-	if len(matches) > 2 {
-		return fmt.Sprintf("%s/%s", matches[1], matches[2])
-	}
-	return url
-}
-
 // Admin handlers
 func (s *Server) deleteAllPosts(c *gin.Context) {
 	// Delete all posts using the generated method
@@ -21,8 +21,15 @@ export const userAPI = {
 // Feed API
 export const feedAPI = {
   getAll: () => apiClient.get('/feeds'),
+  getBroken: () => apiClient.get('/feeds/broken'),
+  enable: (feedId) => apiClient.post(`/feeds/${feedId}/enable`),
+  getFetches: (feedId, limit = 20) => apiClient.get(`/feeds/${feedId}/fetches?limit=${limit}`),
   create: (feedData) => apiClient.post('/feeds', feedData),
   update: (feedId, feedData) => apiClient.put(`/feeds/${feedId}`, feedData),
+  setInterval: (feedId, seconds) =>
+    apiClient.put(`/feeds/${feedId}/interval`, { fetch_interval_seconds: seconds }),
+  setFullText: (feedId, enabled) =>
+    apiClient.put(`/feeds/${feedId}/fulltext`, { fetch_full_text: enabled }),
   delete: (feedId) => apiClient.delete(`/feeds/${feedId}`),
 }
 
@@ -45,8 +52,12 @@ export const postAPI = {
     apiClient.get(`/posts/${userId}?limit=${limit}&offset=${offset}`),
   getUserPostsByFeed: (userId, feedId, limit = 10, offset = 0) => 
     apiClient.get(`/posts/${userId}?limit=${limit}&offset=${offset}&feed_id=${feedId}`),
+  getPost: (userId, postId) =>
+    apiClient.get(`/posts/${userId}/${postId}`),
   fetchUserFeeds: (userId) =>
     apiClient.post(`/feeds/fetch/${userId}`),
+  getRevisions: (postId) =>
+    apiClient.get(`/revisions/${postId}`),
 }
 
 // Bookmark API
@@ -45,9 +45,22 @@ const FeedCard = ({ feed, showActions = true, showFollowButton = true, currentUs
       <div className="space-y-3">
         {/* Feed Info */}
         <div>
-          <h4 className="font-semibold text-gray-900 text-sm leading-tight line-clamp-2 mb-1">
+          <h4 className="font-semibold text-gray-900 text-sm leading-tight line-clamp-2 mb-1 flex items-center gap-1.5">
+            {feed.IconUrl?.Valid && (
+              <img
+                src={feed.IconUrl.String}
+                alt=""
+                className="w-4 h-4 rounded-sm flex-shrink-0"
+                onError={(e) => { e.currentTarget.style.display = 'none' }}
+              />
+            )}
             {feedName}
           </h4>
+          {feed.Description?.Valid && (
+            <p className="text-xs text-gray-700 line-clamp-2 mb-1">
+              {feed.Description.String}
+            </p>
+          )}
           <p className="text-xs text-gray-600 line-clamp-1 mb-1">
             {feedUrl}
           </p>
@@ -138,6 +151,7 @@ function Feeds({ currentUser }) {
   const [error, setError] = useState('')
   const [editingFeed, setEditingFeed] = useState(null)
   const [editFeedData, setEditFeedData] = useState({ name: '', url: '' })
+  const [feedCandidates, setFeedCandidates] = useState([])
 
   const [expandedSections, setExpandedSections] = useState({
     create: false,
@@ -176,9 +190,10 @@ function Feeds({ currentUser }) {
     }
   }
 
-  const createFeed = async (e) => {
-    e.preventDefault()
-    if (!newFeed.name.trim() || !newFeed.url.trim() || !currentUser) return
+  // chosenUrl is set when the user picks one of several feeds a site offers
+  const createFeed = async (e, chosenUrl) => {
+    e?.preventDefault()
+    if (!newFeed.url.trim() || !currentUser) return
 
     // Get the user ID - handle both possible field names
     const userId = currentUser.ID || currentUser.id
@@ -191,17 +206,22 @@ function Feeds({ currentUser }) {
     setError('')
     try {
       // Normalize the URL (just add protocol if missing)
-      const normalizedUrl = normalizeUrl(newFeed.url)
-      
+      const normalizedUrl = chosenUrl || normalizeUrl(newFeed.url)
+
       await feedAPI.create({
         name: newFeed.name,
         url: normalizedUrl,
         user_id: userId
       })
       setNewFeed({ name: '', url: '' })
+      setFeedCandidates([])
       setExpandedSections(prev => ({ ...prev, create: false }))
       fetchFeeds()
     } catch (error) {
+      if (error.response?.status === 300) {
+        setFeedCandidates(error.response.data.candidates || [])
+        return
+      }
       console.error('Error creating feed:', error)
       setError(error.response?.data?.error || 'Failed to create feed')
     } finally {
@@ -410,7 +430,7 @@ function Feeds({ currentUser }) {
                 type="text"
                 value={newFeed.name}
                 onChange={(e) => setNewFeed({ ...newFeed, name: e.target.value })}
-                placeholder="Feed name (e.g., 'Tech News')"
+                placeholder="Feed name (optional, defaults to the feed's title)"
                 className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent"
               />
               <input
@@ -433,6 +453,7 @@ function Feeds({ currentUser }) {
                   type="button"
                   onClick={() => {
                     setNewFeed({ name: '', url: '' })
+                    setFeedCandidates([])
                     setError('')
                   }}
                   className="px-4 py-2 bg-gray-200 text-gray-700 rounded-md hover:bg-gray-300 transition-colors duration-200"
@@ -441,6 +462,24 @@ function Feeds({ currentUser }) {
                 </button>
               </div>
             </form>
+
+            {feedCandidates.length > 0 && (
+              <div className="mt-4 space-y-2">
+                <p className="text-sm font-medium text-gray-700">This site offers several feeds. Pick one:</p>
+                {feedCandidates.map(candidate => (
+                  <button
+                    key={candidate.url}
+                    type="button"
+                    disabled={loading}
+                    onClick={() => createFeed(null, candidate.url)}
+                    className="w-full text-left px-3 py-2 bg-white border border-gray-300 rounded-md hover:bg-blue-50 disabled:opacity-50 transition-colors duration-200"
+                  >
+                    <div className="text-sm font-medium text-gray-900">{candidate.title || candidate.url}</div>
+                    <div className="text-xs text-gray-500 truncate">{candidate.url}</div>
+                  </button>
+                ))}
+              </div>
+            )}
           </div>
         </CollapsibleSection>
 
@@ -12,6 +12,8 @@ function Posts({ currentUser }) {
   const [error, setError] = useState('')
   const [hasMore, setHasMore] = useState(true)
   const [offset, setOffset] = useState(0)
+  const [expandedPost, setExpandedPost] = useState(null)
+  const [articles, setArticles] = useState({})
   const observer = useRef()
   const fetchIntervalRef = useRef()
   const fetchTimeoutRef = useRef()
@@ -299,6 +301,28 @@ function Posts({ currentUser }) {
     console.log('Fetch process manually stopped')
   }
 
+  const toggleArticle = async (post) => {
+    const postId = post.ID || post.id
+    if (expandedPost === postId) {
+      setExpandedPost(null)
+      return
+    }
+    setExpandedPost(postId)
+    if (articles[postId] || !currentUser) return
+
+    try {
+      // Lists only carry the summary, so fetch the full body on demand
+      const response = await postAPI.getPost(currentUser.ID || currentUser.id, postId)
+      const detail = response.data
+      const body = (detail.Content?.Valid && detail.Content.String) || detail.Summary?.String || ''
+      setArticles(prev => ({ ...prev, [postId]: body }))
+    } catch (error) {
+      console.error('Error loading article:', error)
+      setError('Failed to load article')
+      setExpandedPost(null)
+    }
+  }
+
   const toggleBookmark = async (post) => {
     if (!currentUser) return
     
@@ -580,7 +604,18 @@ function Posts({ currentUser }) {
                                 <span className="font-medium">{post.feed_name || post.FeedName}</span>
                               </span>
                               <span className="text-gray-400">•</span>
-                              <span className="text-gray-500">{formatDate(post.published_at || post.PublishedAt)}</span>
+                              <span
+                                className="text-gray-500"
+                                title={post.PublishedAtEstimated ? 'The feed gave no date; this is when the post was first seen' : undefined}
+                              >
+                                {post.PublishedAtEstimated && '~'}{formatDate(post.published_at || post.PublishedAt)}
+                              </span>
+                              {post.Author?.Valid && (
+                                <>
+                                  <span className="text-gray-400">•</span>
+                                  <span className="text-gray-500 truncate">{post.Author.String}</span>
+                                </>
+                              )}
                             </div>
                             
                             {(post.is_read || post.is_bookmarked) && (
@@ -628,6 +663,58 @@ function Posts({ currentUser }) {
                               </p>
                             </div>
                           )}
+
+                          {(post.has_content || post.Summary?.Valid) && (() => {
+                            const postId = post.ID || post.id
+                            const expanded = expandedPost === postId
+                            const article = articles[postId]
+
+                            return (
+                              <div className="space-y-2">
+                                <button
+                                  onClick={() => toggleArticle(post)}
+                                  className="text-xs font-medium text-blue-600 hover:text-blue-800"
+                                >
+                                  {expanded ? 'Hide article' : 'Read article'}
+                                </button>
+                                {expanded && !article && (
+                                  <div className="text-xs text-gray-500">Loading article...</div>
+                                )}
+                                {expanded && article && (
+                                  // Sanitized by the server before it was stored
+                                  <div
+                                    className="text-gray-700 text-sm leading-relaxed space-y-2 break-words [&_a]:text-blue-600 [&_a]:underline [&_img]:max-w-full [&_img]:rounded [&_pre]:bg-gray-100 [&_pre]:p-2 [&_pre]:overflow-x-auto [&_ul]:list-disc [&_ul]:pl-5 [&_ol]:list-decimal [&_ol]:pl-5 [&_blockquote]:border-l-4 [&_blockquote]:pl-3 [&_blockquote]:text-gray-500"
+                                    dangerouslySetInnerHTML={{ __html: article }}
+                                  />
+                                )}
+                              </div>
+                            )
+                          })()}
+
+                          {post.enclosures?.length > 0 && (() => {
+                            const enclosure = post.enclosures[0]
+                            const mimeType = enclosure.MimeType?.Valid ? enclosure.MimeType.String : ''
+                            const thumbnail = enclosure.ThumbnailUrl?.Valid ? enclosure.ThumbnailUrl.String : ''
+
+                            return (
+                              <div className="space-y-2">
+                                {thumbnail && !mimeType.startsWith('video/') && (
+                                  <img
+                                    src={thumbnail}
+                                    alt=""
+                                    loading="lazy"
+                                    className="w-full max-h-40 object-cover rounded-lg"
+                                  />
+                                )}
+                                {mimeType.startsWith('audio/') && (
+                                  <audio controls preload="none" src={enclosure.Url} className="w-full" />
+                                )}
+                                {mimeType.startsWith('video/') && (
+                                  <video controls preload="none" src={enclosure.Url} poster={thumbnail || undefined} className="w-full rounded-lg" />
+                                )}
+                              </div>
+                            )
+                          })()}
                         </div>
                       </div>
                     </div>
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/LFroesch/Gator/internal/database"
 )
@@ -28,10 +29,35 @@ func HandlerBrowse(s *State, cmd Command, user database.User) error {
 
 	fmt.Printf("Found %d posts for user %s:\n", len(posts), user.Name)
 	for _, post := range posts {
-		fmt.Printf("%s from %s\n", post.PublishedAt.Time.Format("Mon Jan 2"), post.FeedName)
-		fmt.Printf("--- %s ---\n", post.Title)
+		// Posts the feed didn't date carry the time we first saw them
+		published := post.PublishedAt.Time.Format("Mon Jan 2")
+		if post.PublishedAtEstimated {
+			published = "~" + published
+		}
+		if post.Author.Valid {
+			fmt.Printf("%s from %s by %s\n", published, post.FeedName, post.Author.String)
+		} else {
+			fmt.Printf("%s from %s\n", published, post.FeedName)
+		}
+		if post.EditedAt.Valid {
+			fmt.Printf("--- %s --- (edited %s)\n", post.Title, post.EditedAt.Time.Format("Mon Jan 2"))
+		} else {
+			fmt.Printf("--- %s ---\n", post.Title)
+		}
 		fmt.Printf("    %v\n", post.Description.String)
 		fmt.Printf("Link: %s\n", post.Url)
+
+		enclosures, err := s.Db.GetPostEnclosures(context.Background(), post.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get enclosures: %w", err)
+		}
+		for _, enclosure := range enclosures {
+			if enclosure.LocalPath.Valid {
+				fmt.Printf("Saved: %s\n", enclosure.LocalPath.String)
+			} else if enclosure.Url != enclosure.ThumbnailUrl.String && !strings.HasPrefix(enclosure.MimeType.String, "image/") {
+				fmt.Printf("Media: %s\n", enclosure.Url)
+			}
+		}
 		fmt.Println("=====================================")
 	}
 
@@ -1,307 +1,141 @@
 package handlers
 
 import (
-	"compress/gzip"
+	"bufio"
 	"context"
 	"database/sql"
-	"encoding/xml"
+	"errors"
 	"fmt"
-	"html"
-	"io"
 	"log"
-	"net/http"
-	"regexp"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/LFroesch/Gator/internal/database"
+	"github.com/LFroesch/Gator/internal/downloader"
+	"github.com/LFroesch/Gator/internal/rss"
+	"github.com/LFroesch/Gator/internal/scraper"
 	"github.com/google/uuid"
-	"golang.org/x/text/encoding/charmap"
-	"golang.org/x/text/transform"
 )
 
-type RSSFeed struct {
-	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
-	} `xml:"channel"`
-}
-
-type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
-}
-
-// AtomFeed represents an Atom feed structure (used by Reddit, YouTube, etc.)
-type AtomFeed struct {
-	Title       string      `xml:"title"`
-	Link        []AtomLink  `xml:"link"`
-	Description string      `xml:"subtitle"`
-	Entry       []AtomEntry `xml:"entry"`
-}
-
-type AtomLink struct {
-	Href string `xml:"href,attr"`
-	Rel  string `xml:"rel,attr"`
-}
-
-type AtomEntry struct {
-	Title            string     `xml:"title"`
-	Link             []AtomLink `xml:"link"`
-	Content          string     `xml:"content"`
-	Summary          string     `xml:"summary"`
-	Published        string     `xml:"published"`
-	Updated          string     `xml:"updated"`
-	MediaDescription string     `xml:"http://search.yahoo.com/mrss/ description"`
-}
-
-// cleanDescription removes HTML tags and cleans up feed descriptions
-func cleanDescription(description string) string {
-	// First, unescape HTML entities so we can properly match HTML tags
-	unescaped := html.UnescapeString(description)
-
-	// Remove HTML comments
-	commentRegex := regexp.MustCompile(`<!--[\s\S]*?-->`)
-	unescaped = commentRegex.ReplaceAllString(unescaped, "")
-
-	// Remove Reddit-specific markers
-	unescaped = strings.ReplaceAll(unescaped, "<!-- SC_OFF -->", "")
-	unescaped = strings.ReplaceAll(unescaped, "<!-- SC_ON -->", "")
-
-	// Remove HTML tags
-	htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
-	cleaned := htmlTagRegex.ReplaceAllString(unescaped, "")
-
-	// Decode common HTML entities that might remain
-	cleaned = strings.ReplaceAll(cleaned, "&amp;", "&")
-	cleaned = strings.ReplaceAll(cleaned, "&lt;", "<")
-	cleaned = strings.ReplaceAll(cleaned, "&gt;", ">")
-	cleaned = strings.ReplaceAll(cleaned, "&quot;", "\"")
-	cleaned = strings.ReplaceAll(cleaned, "&#39;", "'")
-	cleaned = strings.ReplaceAll(cleaned, "&nbsp;", " ")
-
-	// Remove extra whitespace and newlines
-	cleaned = strings.TrimSpace(cleaned)
-	cleaned = regexp.MustCompile(`\s+`).ReplaceAllString(cleaned, " ")
-
-	// Remove Reddit submission line (submitted by /u/username)
-	submittedRegex := regexp.MustCompile(`\s*submitted by\s+/u/\w+\s*`)
-	cleaned = submittedRegex.ReplaceAllString(cleaned, "")
-
-	// Remove [link] and [comments] markers at the end
-	linkCommentsRegex := regexp.MustCompile(`\s*\[link\]\s*\[comments\]\s*$`)
-	cleaned = linkCommentsRegex.ReplaceAllString(cleaned, "")
-
-	// Clean up any remaining extra spaces
-	cleaned = regexp.MustCompile(`\s+`).ReplaceAllString(cleaned, " ")
-	cleaned = strings.TrimSpace(cleaned)
-
-	return cleaned
-}
-
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	// We'll need to:
-	// 1. Create an HTTP request
-	request, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// Set headers that make the request appear more legitimate
-	request.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Gator RSS Reader/1.0; +https://github.com/user/gator)")
-	request.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml, */*")
-	request.Header.Set("Accept-Language", "en-US,en;q=0.9")
-	request.Header.Set("Accept-Encoding", "gzip, deflate")
-	request.Header.Set("Cache-Control", "no-cache")
-	request.Header.Set("Connection", "keep-alive")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	// 2. Send the request
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close() // Always close when done, but deferred to end of func
-
-	// Handle gzipped responses
-	var reader io.Reader = response.Body
-	if response.Header.Get("Content-Encoding") == "gzip" {
-		gzipReader, err := gzip.NewReader(response.Body)
-		if err != nil {
-			return nil, err
-		}
-		defer gzipReader.Close()
-		reader = gzipReader
+func HandlerAgg(s *State, cmd Command) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return fmt.Errorf("usage: %v <time_between_reqs> [concurrency]", cmd.Name)
 	}
 
-	// 3. Read the response
-	body, err := io.ReadAll(reader) // need to read response.Body for the content of the response rather than headers / status codes etc
+	timeBetweenRequests, err := time.ParseDuration(cmd.Args[0])
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("invalid duration: %w", err)
 	}
 
-	// Handle different character encodings
-	bodyStr := string(body)
-	if strings.Contains(bodyStr, `encoding="ISO-8859-1"`) {
-		decoder := charmap.ISO8859_1.NewDecoder()
-		utf8Body, err := io.ReadAll(transform.NewReader(strings.NewReader(bodyStr), decoder))
-		if err != nil {
-			log.Printf("Warning: Failed to convert from ISO-8859-1: %v", err)
-		} else {
-			// Fix the encoding declaration in the XML
-			bodyStr = string(utf8Body)
-			bodyStr = strings.Replace(bodyStr, `encoding="ISO-8859-1"`, `encoding="UTF-8"`, 1)
-			body = []byte(bodyStr)
+	concurrency := 1
+	if len(cmd.Args) == 2 {
+		concurrency, err = strconv.Atoi(cmd.Args[1])
+		if err != nil || concurrency < 1 {
+			return fmt.Errorf("invalid concurrency: %s", cmd.Args[1])
 		}
 	}
-	// 4. Parse the XML - try RSS first, then Atom
-	var feed RSSFeed
-	err = xml.Unmarshal(body, &feed)
-	if err != nil || feed.Channel.Title == "" {
-		// If RSS parsing failed or didn't find channel, try Atom format
-		var atomFeed AtomFeed
-		err = xml.Unmarshal(body, &atomFeed)
-		if err != nil {
-			return nil, err
-		}
-
-		// Convert Atom to RSS format
-		feed.Channel.Title = atomFeed.Title
-		feed.Channel.Description = atomFeed.Description
-
-		// Find the alternate link
-		for _, link := range atomFeed.Link {
-			if link.Rel == "alternate" || link.Rel == "" {
-				feed.Channel.Link = link.Href
-				break
-			}
-		}
+	log.Printf("Collecting feeds every %s with %d workers...", timeBetweenRequests, concurrency)
+	go scraper.RunFullTextWorker(context.Background(), s.Db)
+	startDownloads(s)
 
-		// Convert entries to items
-		feed.Channel.Item = make([]RSSItem, len(atomFeed.Entry))
-		for i, entry := range atomFeed.Entry {
-			feed.Channel.Item[i].Title = entry.Title
-
-			// Find the entry link
-			for _, link := range entry.Link {
-				if link.Rel == "alternate" || link.Rel == "" {
-					feed.Channel.Item[i].Link = link.Href
-					break
-				}
-			}
-
-			// Use content, summary, or media description for description
-			if entry.Content != "" {
-				feed.Channel.Item[i].Description = entry.Content
-			} else if entry.Summary != "" {
-				feed.Channel.Item[i].Description = entry.Summary
-			} else if entry.MediaDescription != "" {
-				feed.Channel.Item[i].Description = entry.MediaDescription
-			}
-
-			// Use published or updated for date
-			if entry.Published != "" {
-				feed.Channel.Item[i].PubDate = entry.Published
-			} else {
-				feed.Channel.Item[i].PubDate = entry.Updated
-			}
-		}
-	}
+	ticker := time.NewTicker(timeBetweenRequests)
 
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
-	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
-	for i := range feed.Channel.Item {
-		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
-		feed.Channel.Item[i].Description = cleanDescription(feed.Channel.Item[i].Description)
+	for ; ; <-ticker.C {
+		scrapeFeeds(s, concurrency)
 	}
-	return &feed, nil
 }
 
-func HandlerAgg(s *State, cmd Command) error {
-	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
-		return fmt.Errorf("usage: %v <time_between_reqs>", cmd.Name)
-	}
+// feedLeaseDuration is how long a claimed feed stays reserved for this
+// process. It must comfortably outlast one fetch; if we crash, the feed is
+// handed to another aggregator once the lease runs out.
+const feedLeaseDuration = 5 * time.Minute
 
-	timeBetweenRequests, err := time.ParseDuration(cmd.Args[0])
+// leaseOwner identifies this aggregator process in feeds.lease_owner
+func leaseOwner() string {
+	hostname, err := os.Hostname()
 	if err != nil {
-		return fmt.Errorf("invalid duration: %w", err)
+		hostname = "unknown"
 	}
-	log.Printf("Collecting feeds every %s...", timeBetweenRequests)
+	return fmt.Sprintf("%s:%d", hostname, os.Getpid())
+}
 
-	ticker := time.NewTicker(timeBetweenRequests)
+// scrapeFeeds runs one round of the aggregator: each worker keeps leasing and
+// scraping feeds until none is due, so one slow feed holds up only its own
+// worker, and the round ends once every worker has run out of feeds
+func scrapeFeeds(s *State, concurrency int) {
+	owner := sql.NullString{String: leaseOwner(), Valid: true}
+
+	// A feed whose next fetch couldn't be scheduled is due again at once, so
+	// it is scraped only once per round rather than over and over
+	var scraped sync.Map
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				feed, err := s.Db.ClaimNextFeedToFetch(context.Background(), database.ClaimNextFeedToFetchParams{
+					LeaseOwner:   owner,
+					LeaseSeconds: int32(feedLeaseDuration.Seconds()),
+				})
+				if errors.Is(err, sql.ErrNoRows) {
+					return // No feed is due yet
+				}
+				if err != nil {
+					log.Println("Couldn't get next feeds to fetch", err)
+					return
+				}
+				_, again := scraped.LoadOrStore(feed.ID, true)
+				if !again {
+					log.Printf("Found a feed to fetch: %s", feed.Name)
+					scrapeFeed(s, feed)
+				}
 
-	for ; ; <-ticker.C {
-		scrapeFeeds(s)
+				err = s.Db.ReleaseFeedLease(context.Background(), database.ReleaseFeedLeaseParams{
+					ID:         feed.ID,
+					LeaseOwner: owner,
+				})
+				if err != nil {
+					log.Printf("Couldn't release lease on feed %s: %v", feed.Name, err)
+				}
+				if again {
+					return
+				}
+			}
+		}()
 	}
+	wg.Wait()
 }
 
-func scrapeFeeds(s *State) {
-	feed, err := s.Db.GetNextFeedToFetch(context.Background())
+func scrapeFeed(s *State, feed database.Feed) {
+	newPosts, err := scraper.ScrapeFeed(context.Background(), s.Conn, s.Db, feed)
 	if err != nil {
-		log.Println("Couldn't get next feeds to fetch", err)
+		log.Printf("Couldn't collect feed %s: %v", feed.Name, err)
 		return
 	}
-	log.Println("Found a feed to fetch!")
-	scrapeFeed(s.Db, feed)
+	log.Printf("Feed %s collected, %d new posts found", feed.Name, newPosts)
 }
 
-func scrapeFeed(db *database.Queries, feed database.Feed) {
-	_, err := db.MarkFeedFetched(context.Background(), feed.ID)
-	if err != nil {
-		log.Printf("Couldn't mark feed %s fetched: %v", feed.Name, err)
-		return
-	}
-
-	feedData, err := FetchFeed(context.Background(), feed.Url)
-	if err != nil {
-		log.Printf("Couldn't collect feed %s: %v", feed.Name, err)
+// startDownloads starts the worker that saves the newest episodes of feeds
+// with downloads turned on to the configured download directory
+func startDownloads(s *State) {
+	if s.Cfg.DownloadDir == "" {
+		feeds, err := s.Db.GetDownloadFeeds(context.Background())
+		if err == nil && len(feeds) > 0 {
+			log.Printf("%d feeds have downloads turned on but no download_dir is configured", len(feeds))
+		}
 		return
 	}
-	for _, item := range feedData.Channel.Item {
-		publishedAt := sql.NullTime{}
-
-		// Try parsing different date formats
-		if item.PubDate != "" {
-			// Try RFC1123Z first (RSS format)
-			if t, err := time.Parse(time.RFC1123Z, item.PubDate); err == nil {
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			} else if t, err := time.Parse(time.RFC1123, item.PubDate); err == nil {
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			} else if t, err := time.Parse(time.RFC3339, item.PubDate); err == nil {
-				// Atom format (ISO 8601)
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			} else if t, err := time.Parse("2006-01-02T15:04:05Z", item.PubDate); err == nil {
-				// Alternative ISO format
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			}
-		}
 
-		_, err = db.CreatePost(context.Background(), database.CreatePostParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now().UTC(),
-			UpdatedAt: time.Now().UTC(),
-			FeedID:    feed.ID,
-			Title:     item.Title,
-			Description: sql.NullString{
-				String: item.Description,
-				Valid:  true,
-			},
-			Url:         item.Link,
-			PublishedAt: publishedAt,
-		})
-		if err != nil {
-			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-				continue
-			}
-			log.Printf("Couldn't create post: %v", err)
-			continue
-		}
+	d := &downloader.Downloader{
+		Dir:      s.Cfg.DownloadDir,
+		MaxBytes: s.Cfg.MaxDownloadMB << 20,
 	}
-	log.Printf("Feed %s collected, %v posts found", feed.Name, len(feedData.Channel.Item))
+	go d.Run(context.Background(), s.Db)
 }
 
 func HandlerAddFeed(s *State, cmd Command, user database.User) error {
@@ -310,21 +144,29 @@ func HandlerAddFeed(s *State, cmd Command, user database.User) error {
 		return err
 	}
 
-	if len(cmd.Args) != 2 {
-		return fmt.Errorf("| usage: %s <name> <url>", cmd.Name)
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return fmt.Errorf("| usage: %s [name] <url>", cmd.Name)
 	}
 
-	name := cmd.Args[0]
-	url := cmd.Args[1]
+	// Without a name the feed is called by its own title
+	name := ""
+	pageURL := cmd.Args[0]
+	if len(cmd.Args) == 2 {
+		name, pageURL = cmd.Args[0], cmd.Args[1]
+	}
 
-	feed, err := s.Db.CreateFeed(context.Background(), database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
-		UserID:    user.ID,
-		Name:      name,
-		Url:       url,
-	})
+	candidate, err := chooseFeed(pageURL)
+	if err != nil {
+		return err
+	}
+
+	// Make sure it really is a feed before storing it
+	parsed, err := rss.Probe(context.Background(), candidate)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid feed: %w", candidate.URL, err)
+	}
+
+	feed, err := scraper.AddFeed(context.Background(), s.Db, user.ID, name, candidate.URL, parsed)
 	if err != nil {
 		return fmt.Errorf("couldn't create feed: %w", err)
 	}
@@ -351,6 +193,41 @@ func HandlerAddFeed(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
+// chooseFeed resolves what the user typed into a feed. Website URLs are
+// searched for feeds, and when a site offers several the user picks one.
+func chooseFeed(pageURL string) (rss.Candidate, error) {
+	candidates, err := rss.Discover(context.Background(), pageURL)
+	if err != nil {
+		return rss.Candidate{}, fmt.Errorf("couldn't fetch %s: %w", pageURL, err)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return rss.Candidate{}, fmt.Errorf("no RSS, Atom or JSON feed found at %s", pageURL)
+	case 1:
+		if candidates[0].URL != pageURL {
+			fmt.Printf("Found feed at %s\n", candidates[0].URL)
+		}
+		return candidates[0], nil
+	}
+
+	fmt.Printf("%s offers %d feeds:\n", pageURL, len(candidates))
+	for i, candidate := range candidates {
+		fmt.Printf("  %d) %s (%s)\n", i+1, candidate.Title, candidate.URL)
+	}
+	fmt.Printf("Pick a feed [1-%d]: ", len(candidates))
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return rss.Candidate{}, fmt.Errorf("no feed picked; run addfeed again with one of the URLs above")
+	}
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(candidates) {
+		return rss.Candidate{}, fmt.Errorf("invalid choice: %s", strings.TrimSpace(line))
+	}
+	return candidates[choice-1], nil
+}
+
 func printFeed(feed database.Feed) {
 	fmt.Printf("* ID:            %s\n", feed.ID)
 	fmt.Printf("* Created:       %v\n", feed.CreatedAt)
@@ -358,6 +235,15 @@ func printFeed(feed database.Feed) {
 	fmt.Printf("* Name:          %s\n", feed.Name)
 	fmt.Printf("* URL:           %s\n", feed.Url)
 	fmt.Printf("* UserID:        %s\n", feed.UserID)
+	if feed.SiteUrl.Valid {
+		fmt.Printf("* Site:          %s\n", feed.SiteUrl.String)
+	}
+	if feed.Description.Valid {
+		fmt.Printf("* Description:   %s\n", feed.Description.String)
+	}
+	if feed.Language.Valid {
+		fmt.Printf("* Language:      %s\n", feed.Language.String)
+	}
 }
 
 func printFeedWithUser(feed database.GetAllFeedsRow) {
@@ -366,7 +252,31 @@ func printFeedWithUser(feed database.GetAllFeedsRow) {
 	fmt.Printf("* UserName:      %s\n", feed.Username)
 }
 
+func printBrokenFeed(feed database.Feed) {
+	status := "failing"
+	if feed.DisabledAt.Valid {
+		status = "disabled since " + feed.DisabledAt.Time.Format("Mon Jan 2 15:04")
+	}
+	lastSuccess := "never"
+	if feed.LastSuccessAt.Valid {
+		lastSuccess = feed.LastSuccessAt.Time.Format("Mon Jan 2 15:04")
+	}
+	fmt.Printf("* Name:          %s\n", feed.Name)
+	fmt.Printf("* URL:           %s\n", feed.Url)
+	fmt.Printf("* Status:        %s\n", status)
+	fmt.Printf("* Failures:      %d in a row\n", feed.ConsecutiveFailures)
+	fmt.Printf("* Last success:  %s\n", lastSuccess)
+	fmt.Printf("* Last error:    %s\n", feed.LastError.String)
+}
+
 func HandlerPrintAllFeeds(s *State, cmd Command) error {
+	if len(cmd.Args) == 1 && cmd.Args[0] == "--broken" {
+		return printBrokenFeeds(s)
+	}
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s [--broken]", cmd.Name)
+	}
+
 	feeds, err := s.Db.GetAllFeeds(context.Background())
 	if err != nil {
 		return err
@@ -382,3 +292,196 @@ func HandlerPrintAllFeeds(s *State, cmd Command) error {
 	}
 	return nil
 }
+
+func printBrokenFeeds(s *State) error {
+	feeds, err := s.Db.GetBrokenFeeds(context.Background())
+	if err != nil {
+		return fmt.Errorf("couldn't get broken feeds: %w", err)
+	}
+	if len(feeds) == 0 {
+		fmt.Println("All feeds are healthy.")
+		return nil
+	}
+
+	fmt.Printf("Found %d unhealthy feeds:\n", len(feeds))
+	for _, feed := range feeds {
+		printBrokenFeed(feed)
+		fmt.Println()
+	}
+	return nil
+}
+
+func HandlerEnableFeed(s *State, cmd Command) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <feed_url>", cmd.Name)
+	}
+
+	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't get feed: %w", err)
+	}
+
+	feed, err = s.Db.EnableFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't enable feed: %w", err)
+	}
+
+	fmt.Printf("Feed %s re-enabled and will be fetched on the next round\n", feed.Name)
+	return nil
+}
+
+func HandlerFetches(s *State, cmd Command) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return fmt.Errorf("usage: %s <feed_url> [limit]", cmd.Name)
+	}
+
+	limit := 10
+	if len(cmd.Args) == 2 {
+		if specifiedLimit, err := strconv.Atoi(cmd.Args[1]); err == nil {
+			limit = specifiedLimit
+		} else {
+			return fmt.Errorf("invalid limit: %w", err)
+		}
+	}
+
+	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't get feed: %w", err)
+	}
+
+	fetches, err := s.Db.GetFeedFetches(context.Background(), database.GetFeedFetchesParams{
+		FeedID: feed.ID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get fetch history: %w", err)
+	}
+	if len(fetches) == 0 {
+		fmt.Printf("Feed %s has not been fetched yet.\n", feed.Name)
+		return nil
+	}
+
+	fmt.Printf("Last %d fetches of %s:\n", len(fetches), feed.Name)
+	for _, fetch := range fetches {
+		status := "-"
+		if fetch.StatusCode.Valid {
+			status = strconv.Itoa(int(fetch.StatusCode.Int32))
+		}
+		fmt.Printf("%s | status %s | %d bytes | %d items | %d new | %dms\n",
+			fetch.StartedAt.Format("Mon Jan 2 15:04:05"), status, fetch.Bytes,
+			fetch.ItemCount, fetch.NewPostCount, fetch.DurationMs)
+		if fetch.Truncated {
+			fmt.Println("    truncated: the feed went past the size limit")
+		}
+		if fetch.Error.Valid {
+			fmt.Printf("    error: %s\n", fetch.Error.String)
+		}
+	}
+	return nil
+}
+
+func HandlerSetInterval(s *State, cmd Command) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <feed_url> <interval|auto>", cmd.Name)
+	}
+
+	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't get feed: %w", err)
+	}
+
+	// "auto" clears the override and goes back to the feed's own hints
+	interval := sql.NullInt32{}
+	if cmd.Args[1] != "auto" {
+		d, err := time.ParseDuration(cmd.Args[1])
+		if err != nil || d < time.Minute {
+			return fmt.Errorf("invalid interval %q: use a duration of at least 1m, or auto", cmd.Args[1])
+		}
+		interval = sql.NullInt32{Int32: int32(d.Seconds()), Valid: true}
+	}
+
+	feed, err = s.Db.SetFeedFetchInterval(context.Background(), database.SetFeedFetchIntervalParams{
+		ID:                   feed.ID,
+		FetchIntervalSeconds: interval,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set refresh interval: %w", err)
+	}
+
+	if interval.Valid {
+		fmt.Printf("Feed %s will be refreshed every %s\n", feed.Name, time.Duration(interval.Int32)*time.Second)
+	} else {
+		fmt.Printf("Feed %s will be refreshed automatically\n", feed.Name)
+	}
+	return nil
+}
+
+func HandlerSetFullText(s *State, cmd Command) error {
+	if len(cmd.Args) != 2 || (cmd.Args[1] != "on" && cmd.Args[1] != "off") {
+		return fmt.Errorf("usage: %s <feed_url> <on|off>", cmd.Name)
+	}
+
+	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't get feed: %w", err)
+	}
+
+	feed, err = s.Db.SetFeedFullText(context.Background(), database.SetFeedFullTextParams{
+		ID:            feed.ID,
+		FetchFullText: cmd.Args[1] == "on",
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update full text: %w", err)
+	}
+
+	if feed.FetchFullText {
+		fmt.Printf("agg will download the full article for new posts from %s\n", feed.Name)
+	} else {
+		fmt.Printf("Full text turned off for %s\n", feed.Name)
+	}
+	return nil
+}
+
+func HandlerSetDownloads(s *State, cmd Command) error {
+	if len(cmd.Args) < 2 || len(cmd.Args) > 3 || (cmd.Args[1] != "on" && cmd.Args[1] != "off") {
+		return fmt.Errorf("usage: %s <feed_url> <on|off> [keep]", cmd.Name)
+	}
+
+	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't get feed: %w", err)
+	}
+
+	// keep is how many of the newest episodes stay on disk; without it
+	// nothing is ever deleted
+	retention := sql.NullInt32{}
+	if len(cmd.Args) == 3 {
+		keep, err := strconv.Atoi(cmd.Args[2])
+		if err != nil || keep < 1 {
+			return fmt.Errorf("invalid keep count: %s", cmd.Args[2])
+		}
+		retention = sql.NullInt32{Int32: int32(keep), Valid: true}
+	}
+
+	feed, err = s.Db.SetFeedDownloads(context.Background(), database.SetFeedDownloadsParams{
+		ID:                 feed.ID,
+		DownloadEnclosures: cmd.Args[1] == "on",
+		DownloadRetention:  retention,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update downloads: %w", err)
+	}
+
+	switch {
+	case !feed.DownloadEnclosures:
+		fmt.Printf("Downloads turned off for %s\n", feed.Name)
+	case feed.DownloadRetention.Valid:
+		fmt.Printf("agg will keep the newest %d episodes of %s in %s\n", feed.DownloadRetention.Int32, feed.Name, s.Cfg.DownloadDir)
+	default:
+		fmt.Printf("agg will download new episodes of %s to %s\n", feed.Name, s.Cfg.DownloadDir)
+	}
+	if feed.DownloadEnclosures && s.Cfg.DownloadDir == "" {
+		fmt.Println("Note: set download_dir in your config file before running agg")
+	}
+	return nil
+}
@@ -128,11 +128,16 @@ func HandlerHelp(s *State, cmd Command) error {
 	fmt.Println("reset     | go run . reset                   | Resets all tables")
 	fmt.Println("--- Feed Management ---")
 	// LOOK INTO THESE, USE LOGIN HANDLER for ADDFEED? Remove exclusivity? Add more searches / paging
-	fmt.Println("addfeed   | go run . addfeed <name> <url>    | Adds <url>(feed) to current signed in user as an RSSfeed named <name>")
-	fmt.Println("agg       | go run . agg <time_between_reqs> | Pulls RSSdata from your feeds with a <time_between_reqs> refresher - CTRL + C to stop")
+	fmt.Println("addfeed   | go run . addfeed [name] <url>  | Adds <url>(feed) to current signed in user, named [name] or the feed's own title (website URLs are searched for their feed)")
+	fmt.Println("agg       | go run . agg <time_between_reqs> [concurrency] | Pulls RSSdata from your feeds with a <time_between_reqs> refresher, using [concurrency] workers (def: 1) - CTRL + C to stop")
 	fmt.Println("follow    | go run . follow <url>            | Follows <url> as current signed in user")
 	fmt.Println("unfollow  | go run . unfollow <url>          | Unfollows <url> as current signed in user")
-	fmt.Println("feeds     | go run . feeds                   | Lists all feeds and their 'main' user")
+	fmt.Println("feeds     | go run . feeds [--broken]        | Lists all feeds and their 'main' user, or only failing/disabled feeds with --broken")
+	fmt.Println("enable    | go run . enable <url>            | Re-enables a feed that was disabled after failing too long")
+	fmt.Println("fetches   | go run . fetches <url> <limit (def: 10)> | Shows the most recent fetch attempts for <url> with status, size and errors")
+	fmt.Println("interval  | go run . interval <url> <interval|auto> | Overrides how often <url> is refreshed (e.g. 30m), or 'auto' to follow the feed's own hints")
+	fmt.Println("fulltext  | go run . fulltext <url> <on|off>  | Stores the linked article's full text instead of the feed's teaser for new posts")
+	fmt.Println("downloads | go run . downloads <url> <on|off> [keep] | Lets agg save <url>'s podcast/video enclosures to download_dir, keeping the newest [keep]")
 	fmt.Println("following | go run . following               | Lists the current users followed URLs/RSSfeeds")
 	fmt.Println("browse    | go run . browse <limit (def: 2)> | Lists the newest posts in current users feed")
 	return nil
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"database/sql"
+
 	"github.com/LFroesch/Gator/internal/config"
 	"github.com/LFroesch/Gator/internal/database"
 )
@@ -8,4 +10,6 @@ import (
 type State struct {
 	Db  *database.Queries
 	Cfg *config.Config
+	// Conn is the connection Db runs on, for work that needs a transaction
+	Conn *sql.DB
 }
@@ -10,6 +10,16 @@ const configFileName = ".gatorconfig.json"
 type Config struct {
 	DBURL           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+
+	// Where agg saves enclosures for feeds with downloads turned on, and the
+	// largest file it will fetch (0 uses the downloader's default)
+	DownloadDir   string `json:"download_dir,omitempty"`
+	MaxDownloadMB int64  `json:"max_download_mb,omitempty"`
+
+	// The most of a single feed document that is read and how many of its
+	// items are kept (0 uses the fetcher's defaults)
+	MaxFeedMB    int64 `json:"max_feed_mb,omitempty"`
+	MaxFeedItems int   `json:"max_feed_items,omitempty"`
 }
 
 func (cfg *Config) SetUser(userName string) error {
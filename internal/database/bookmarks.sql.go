@@ -141,3 +141,26 @@ func (q *Queries) IsPostBookmarked(ctx context.Context, arg IsPostBookmarkedPara
 	err := row.Scan(&is_bookmarked)
 	return is_bookmarked, err
 }
+
+const moveDuplicateBookmarks = `-- name: MoveDuplicateBookmarks :exec
+UPDATE bookmarks
+SET post_id = target.id, updated_at = NOW()
+FROM posts source, posts target
+WHERE bookmarks.post_id = source.id
+  AND source.feed_id = $1
+  AND target.feed_id = $2
+  AND target.guid = source.guid
+  AND NOT EXISTS (SELECT 1 FROM bookmarks existing WHERE existing.user_id = bookmarks.user_id AND existing.post_id = target.id)
+`
+
+type MoveDuplicateBookmarksParams struct {
+	FromFeedID uuid.UUID
+	ToFeedID   uuid.UUID
+}
+
+// Repoints bookmarks on posts of one feed at the post with the same guid in
+// another, skipping users who already have one there
+func (q *Queries) MoveDuplicateBookmarks(ctx context.Context, arg MoveDuplicateBookmarksParams) error {
+	_, err := q.db.ExecContext(ctx, moveDuplicateBookmarks, arg.FromFeedID, arg.ToFeedID)
+	return err
+}
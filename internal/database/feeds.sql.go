@@ -7,31 +7,101 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const claimNextFeedToFetch = `-- name: ClaimNextFeedToFetch :one
+UPDATE feeds
+SET lease_owner = $1,
+    lease_expires_at = NOW() + make_interval(secs => $2::int),
+    updated_at = NOW()
+WHERE id = (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL
+      AND (lease_expires_at IS NULL OR lease_expires_at < NOW())
+      AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    ORDER BY next_fetch_at NULLS FIRST, last_fetched_at NULLS FIRST
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
+`
+
+type ClaimNextFeedToFetchParams struct {
+	LeaseOwner   sql.NullString
+	LeaseSeconds int32
+}
+
+// Leases the most overdue unleased feed to lease_owner. SKIP LOCKED keeps
+// concurrent workers and other agg processes from claiming the same feed, and
+// a lease left behind by a crashed worker is reclaimable once it expires
+func (q *Queries) ClaimNextFeedToFetch(ctx context.Context, arg ClaimNextFeedToFetchParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, claimNextFeedToFetch, arg.LeaseOwner, arg.LeaseSeconds)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
+	)
+	return i, err
+}
+
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, title, site_url, description, language, icon_url)
 VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
 `
 
 type CreateFeedParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserID    uuid.UUID
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string
+	Url         string
+	UserID      uuid.UUID
+	Title       sql.NullString
+	SiteUrl     sql.NullString
+	Description sql.NullString
+	Language    sql.NullString
+	IconUrl     sql.NullString
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -42,6 +112,11 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.Name,
 		arg.Url,
 		arg.UserID,
+		arg.Title,
+		arg.SiteUrl,
+		arg.Description,
+		arg.Language,
+		arg.IconUrl,
 	)
 	var i Feed
 	err := row.Scan(
@@ -52,6 +127,25 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
 	)
 	return i, err
 }
@@ -65,18 +159,109 @@ func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const disableFeed = `-- name: DisableFeed :one
+UPDATE feeds
+SET disabled_at = NOW(), last_error = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
+`
+
+type DisableFeedParams struct {
+	ID        uuid.UUID
+	LastError sql.NullString
+}
+
+// Disables a feed right away, for servers that say it is gone for good
+func (q *Queries) DisableFeed(ctx context.Context, arg DisableFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, disableFeed, arg.ID, arg.LastError)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
+	)
+	return i, err
+}
+
+const enableFeed = `-- name: EnableFeed :one
+UPDATE feeds
+SET disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
+`
+
+func (q *Queries) EnableFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, enableFeed, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
+	)
+	return i, err
+}
+
 const getAllFeeds = `-- name: GetAllFeeds :many
-SELECT feeds.id, feeds.name, feeds.url, feeds.user_id, users.name as username
+SELECT feeds.id, feeds.name, feeds.url, feeds.user_id, feeds.description, feeds.site_url, feeds.icon_url, users.name as username
 FROM feeds
 JOIN users ON feeds.user_id = users.id
 `
 
 type GetAllFeedsRow struct {
-	ID       uuid.UUID
-	Name     string
-	Url      string
-	UserID   uuid.UUID
-	Username string
+	ID          uuid.UUID
+	Name        string
+	Url         string
+	UserID      uuid.UUID
+	Description sql.NullString
+	SiteUrl     sql.NullString
+	IconUrl     sql.NullString
+	Username    string
 }
 
 func (q *Queries) GetAllFeeds(ctx context.Context) ([]GetAllFeedsRow, error) {
@@ -93,6 +278,9 @@ func (q *Queries) GetAllFeeds(ctx context.Context) ([]GetAllFeedsRow, error) {
 			&i.Name,
 			&i.Url,
 			&i.UserID,
+			&i.Description,
+			&i.SiteUrl,
+			&i.IconUrl,
 			&i.Username,
 		); err != nil {
 			return nil, err
@@ -108,8 +296,121 @@ func (q *Queries) GetAllFeeds(ctx context.Context) ([]GetAllFeedsRow, error) {
 	return items, nil
 }
 
+const getBrokenFeeds = `-- name: GetBrokenFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text FROM feeds
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY disabled_at NULLS LAST, consecutive_failures DESC
+`
+
+func (q *Queries) GetBrokenFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getBrokenFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+			&i.NextFetchAt,
+			&i.FeedTtlSeconds,
+			&i.FetchIntervalSeconds,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+			&i.DownloadEnclosures,
+			&i.DownloadRetention,
+			&i.Title,
+			&i.SiteUrl,
+			&i.Description,
+			&i.Language,
+			&i.IconUrl,
+			&i.FetchFullText,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDownloadFeeds = `-- name: GetDownloadFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text FROM feeds
+WHERE download_enclosures AND disabled_at IS NULL
+ORDER BY last_fetched_at DESC NULLS LAST
+`
+
+// Feeds with enclosure downloads turned on, for agg's download worker
+func (q *Queries) GetDownloadFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getDownloadFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+			&i.NextFetchAt,
+			&i.FeedTtlSeconds,
+			&i.FetchIntervalSeconds,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+			&i.DownloadEnclosures,
+			&i.DownloadRetention,
+			&i.Title,
+			&i.SiteUrl,
+			&i.Description,
+			&i.Language,
+			&i.IconUrl,
+			&i.FetchFullText,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeedByID = `-- name: GetFeedByID :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE id = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text FROM feeds WHERE id = $1
 `
 
 func (q *Queries) GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error) {
@@ -123,12 +424,31 @@ func (q *Queries) GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
 	)
 	return i, err
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text FROM feeds
 ORDER BY last_fetched_at NULLS FIRST
 LIMIT 1
 `
@@ -144,6 +464,25 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
 	)
 	return i, err
 }
@@ -152,7 +491,7 @@ const markFeedFetched = `-- name: MarkFeedFetched :one
 UPDATE feeds
 SET last_fetched_at = NOW(), updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
 `
 
 func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, error) {
@@ -166,6 +505,267 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, erro
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
+	)
+	return i, err
+}
+
+const recordFeedFailure = `-- name: RecordFeedFailure :one
+UPDATE feeds
+SET consecutive_failures = consecutive_failures + 1,
+    last_error = $1,
+    disabled_at = CASE
+        WHEN COALESCE(last_success_at, created_at) < NOW() - make_interval(days => $2::int)
+        THEN NOW()
+        ELSE disabled_at
+    END,
+    updated_at = NOW()
+WHERE id = $3
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
+`
+
+type RecordFeedFailureParams struct {
+	LastError        sql.NullString
+	DisableAfterDays int32
+	ID               uuid.UUID
+}
+
+// Counts a failed fetch and disables the feed once it has gone
+// disable_after_days without a single successful fetch. A feed that has
+// never been fetched successfully counts from when it was added.
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, recordFeedFailure, arg.LastError, arg.DisableAfterDays, arg.ID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
+	)
+	return i, err
+}
+
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET last_success_at = NOW(), consecutive_failures = 0, last_error = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RecordFeedSuccess(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess, id)
+	return err
+}
+
+const releaseFeedLease = `-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), lease_owner = NULL, lease_expires_at = NULL, updated_at = NOW()
+WHERE id = $1 AND lease_owner = $2
+`
+
+type ReleaseFeedLeaseParams struct {
+	ID         uuid.UUID
+	LeaseOwner sql.NullString
+}
+
+func (q *Queries) ReleaseFeedLease(ctx context.Context, arg ReleaseFeedLeaseParams) error {
+	_, err := q.db.ExecContext(ctx, releaseFeedLease, arg.ID, arg.LeaseOwner)
+	return err
+}
+
+const scheduleNextFetch = `-- name: ScheduleNextFetch :exec
+UPDATE feeds
+SET feed_ttl_seconds = $2, next_fetch_at = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type ScheduleNextFetchParams struct {
+	ID             uuid.UUID
+	FeedTtlSeconds sql.NullInt32
+	NextFetchAt    sql.NullTime
+}
+
+func (q *Queries) ScheduleNextFetch(ctx context.Context, arg ScheduleNextFetchParams) error {
+	_, err := q.db.ExecContext(ctx, scheduleNextFetch, arg.ID, arg.FeedTtlSeconds, arg.NextFetchAt)
+	return err
+}
+
+const setFeedDownloads = `-- name: SetFeedDownloads :one
+UPDATE feeds
+SET download_enclosures = $2, download_retention = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
+`
+
+type SetFeedDownloadsParams struct {
+	ID                 uuid.UUID
+	DownloadEnclosures bool
+	DownloadRetention  sql.NullInt32
+}
+
+func (q *Queries) SetFeedDownloads(ctx context.Context, arg SetFeedDownloadsParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedDownloads, arg.ID, arg.DownloadEnclosures, arg.DownloadRetention)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
+	)
+	return i, err
+}
+
+const setFeedFetchInterval = `-- name: SetFeedFetchInterval :one
+UPDATE feeds
+SET fetch_interval_seconds = $2, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
+`
+
+type SetFeedFetchIntervalParams struct {
+	ID                   uuid.UUID
+	FetchIntervalSeconds sql.NullInt32
+}
+
+// Overrides the refresh interval (NULL returns to automatic) and makes the
+// feed due immediately so the new interval takes effect on the next round
+func (q *Queries) SetFeedFetchInterval(ctx context.Context, arg SetFeedFetchIntervalParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedFetchInterval, arg.ID, arg.FetchIntervalSeconds)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
+	)
+	return i, err
+}
+
+const setFeedFullText = `-- name: SetFeedFullText :one
+UPDATE feeds
+SET fetch_full_text = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
+`
+
+type SetFeedFullTextParams struct {
+	ID            uuid.UUID
+	FetchFullText bool
+}
+
+func (q *Queries) SetFeedFullText(ctx context.Context, arg SetFeedFullTextParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedFullText, arg.ID, arg.FetchFullText)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
 	)
 	return i, err
 }
@@ -174,7 +774,7 @@ const updateFeed = `-- name: UpdateFeed :one
 UPDATE feeds 
 SET name = $2, url = $3, updated_at = $4
 WHERE id = $1
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, lease_owner, lease_expires_at, next_fetch_at, feed_ttl_seconds, fetch_interval_seconds, last_error, consecutive_failures, last_success_at, disabled_at, download_enclosures, download_retention, title, site_url, description, language, icon_url, fetch_full_text
 `
 
 type UpdateFeedParams struct {
@@ -200,6 +800,85 @@ func (q *Queries) UpdateFeed(ctx context.Context, arg UpdateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FeedTtlSeconds,
+		&i.FetchIntervalSeconds,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.DownloadEnclosures,
+		&i.DownloadRetention,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.IconUrl,
+		&i.FetchFullText,
 	)
 	return i, err
 }
+
+const updateFeedCacheHeaders = `-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds
+SET etag = $2, last_modified = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedCacheHeadersParams struct {
+	ID           uuid.UUID
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) UpdateFeedCacheHeaders(ctx context.Context, arg UpdateFeedCacheHeadersParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedCacheHeaders, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
+
+const updateFeedMetadata = `-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET title = $2, site_url = $3, description = $4, language = $5, icon_url = $6, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedMetadataParams struct {
+	ID          uuid.UUID
+	Title       sql.NullString
+	SiteUrl     sql.NullString
+	Description sql.NullString
+	Language    sql.NullString
+	IconUrl     sql.NullString
+}
+
+func (q *Queries) UpdateFeedMetadata(ctx context.Context, arg UpdateFeedMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedMetadata,
+		arg.ID,
+		arg.Title,
+		arg.SiteUrl,
+		arg.Description,
+		arg.Language,
+		arg.IconUrl,
+	)
+	return err
+}
+
+const updateFeedURL = `-- name: UpdateFeedURL :exec
+UPDATE feeds
+SET url = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedURLParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedURL, arg.ID, arg.Url)
+	return err
+}
@@ -20,13 +20,47 @@ type Bookmark struct {
 }
 
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.UUID
+	LastFetchedAt        sql.NullTime
+	Etag                 sql.NullString
+	LastModified         sql.NullString
+	LeaseOwner           sql.NullString
+	LeaseExpiresAt       sql.NullTime
+	NextFetchAt          sql.NullTime
+	FeedTtlSeconds       sql.NullInt32
+	FetchIntervalSeconds sql.NullInt32
+	LastError            sql.NullString
+	ConsecutiveFailures  int32
+	LastSuccessAt        sql.NullTime
+	DisabledAt           sql.NullTime
+	DownloadEnclosures   bool
+	DownloadRetention    sql.NullInt32
+	Title                sql.NullString
+	SiteUrl              sql.NullString
+	Description          sql.NullString
+	Language             sql.NullString
+	IconUrl              sql.NullString
+	FetchFullText        bool
+}
+
+type FeedFetch struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	FeedID       uuid.UUID
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	StatusCode   sql.NullInt32
+	Bytes        int64
+	ItemCount    int32
+	NewPostCount int32
+	DurationMs   int32
+	Error        sql.NullString
+	Truncated    bool
 }
 
 type FeedFollow struct {
@@ -37,15 +71,43 @@ type FeedFollow struct {
 	FeedID    uuid.UUID
 }
 
+type FullTextJob struct {
+	PostID    uuid.UUID
+	Url       string
+	CreatedAt time.Time
+}
+
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	Guid                 string
+	SourceUpdatedAt      sql.NullTime
+	EditedAt             sql.NullTime
+	Author               sql.NullString
+	Content              sql.NullString
+	Summary              sql.NullString
+	PublishedAtEstimated bool
+}
+
+type PostEnclosure struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	PostID          uuid.UUID
+	Url             string
+	MimeType        sql.NullString
+	Length          sql.NullInt64
+	DurationSeconds sql.NullInt32
+	ThumbnailUrl    sql.NullString
+	LocalPath       sql.NullString
+	DownloadedAt    sql.NullTime
+	DownloadError   sql.NullString
 }
 
 type PostRead struct {
@@ -57,6 +119,15 @@ type PostRead struct {
 	ReadAt    time.Time
 }
 
+type PostRevision struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	PostID          uuid.UUID
+	Title           string
+	Description     sql.NullString
+	SourceUpdatedAt sql.NullTime
+}
+
 type User struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
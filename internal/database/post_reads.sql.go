@@ -144,3 +144,26 @@ func (q *Queries) IsPostRead(ctx context.Context, arg IsPostReadParams) (bool, e
 	err := row.Scan(&is_read)
 	return is_read, err
 }
+
+const moveDuplicatePostReads = `-- name: MoveDuplicatePostReads :exec
+UPDATE post_reads
+SET post_id = target.id, updated_at = NOW()
+FROM posts source, posts target
+WHERE post_reads.post_id = source.id
+  AND source.feed_id = $1
+  AND target.feed_id = $2
+  AND target.guid = source.guid
+  AND NOT EXISTS (SELECT 1 FROM post_reads existing WHERE existing.user_id = post_reads.user_id AND existing.post_id = target.id)
+`
+
+type MoveDuplicatePostReadsParams struct {
+	FromFeedID uuid.UUID
+	ToFeedID   uuid.UUID
+}
+
+// Repoints post_reads on posts of one feed at the post with the same guid in
+// another, skipping users who already have one there
+func (q *Queries) MoveDuplicatePostReads(ctx context.Context, arg MoveDuplicatePostReadsParams) error {
+	_, err := q.db.ExecContext(ctx, moveDuplicatePostReads, arg.FromFeedID, arg.ToFeedID)
+	return err
+}
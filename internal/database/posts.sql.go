@@ -13,25 +13,33 @@ import (
 	"github.com/google/uuid"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+const createPost = `-- name: CreatePost :execrows
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid, source_updated_at, author, content, summary, published_at_estimated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (feed_id, guid) DO NOTHING
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
-}
-
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	Guid                 string
+	SourceUpdatedAt      sql.NullTime
+	Author               sql.NullString
+	Content              sql.NullString
+	Summary              sql.NullString
+	PublishedAtEstimated bool
+}
+
+// Inserts a post unless the feed already has one with the same guid; the
+// affected row count tells the caller whether the post was new
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createPost,
 		arg.ID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
@@ -40,7 +48,40 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Guid,
+		arg.SourceUpdatedAt,
+		arg.Author,
+		arg.Content,
+		arg.Summary,
+		arg.PublishedAtEstimated,
 	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAllPosts = `-- name: DeleteAllPosts :exec
+DELETE FROM posts
+`
+
+func (q *Queries) DeleteAllPosts(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllPosts)
+	return err
+}
+
+const getPostByFeedAndGuid = `-- name: GetPostByFeedAndGuid :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, guid, source_updated_at, edited_at, author, content, summary, published_at_estimated FROM posts
+WHERE feed_id = $1 AND guid = $2
+`
+
+type GetPostByFeedAndGuidParams struct {
+	FeedID uuid.UUID
+	Guid   string
+}
+
+func (q *Queries) GetPostByFeedAndGuid(ctx context.Context, arg GetPostByFeedAndGuidParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByFeedAndGuid, arg.FeedID, arg.Guid)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -51,21 +92,68 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Guid,
+		&i.SourceUpdatedAt,
+		&i.EditedAt,
+		&i.Author,
+		&i.Content,
+		&i.Summary,
+		&i.PublishedAtEstimated,
 	)
 	return i, err
 }
 
-const deleteAllPosts = `-- name: DeleteAllPosts :exec
-DELETE FROM posts
+const getPostByID = `-- name: GetPostByID :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.source_updated_at, posts.edited_at, posts.author, posts.content, posts.summary, posts.published_at_estimated, feeds.name AS feed_name FROM posts
+JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.id = $1
 `
 
-func (q *Queries) DeleteAllPosts(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteAllPosts)
-	return err
+type GetPostByIDRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	Guid                 string
+	SourceUpdatedAt      sql.NullTime
+	EditedAt             sql.NullTime
+	Author               sql.NullString
+	Content              sql.NullString
+	Summary              sql.NullString
+	PublishedAtEstimated bool
+	FeedName             string
+}
+
+func (q *Queries) GetPostByID(ctx context.Context, id uuid.UUID) (GetPostByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostByID, id)
+	var i GetPostByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Guid,
+		&i.SourceUpdatedAt,
+		&i.EditedAt,
+		&i.Author,
+		&i.Content,
+		&i.Summary,
+		&i.PublishedAtEstimated,
+		&i.FeedName,
+	)
+	return i, err
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, feeds.name AS feed_name FROM posts
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.source_updated_at, posts.edited_at, posts.author, posts.content, posts.summary, posts.published_at_estimated, feeds.name AS feed_name FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 JOIN feeds ON posts.feed_id = feeds.id
 WHERE feed_follows.user_id = $1
@@ -79,15 +167,22 @@ type GetPostsForUserParams struct {
 }
 
 type GetPostsForUserRow struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
-	FeedName    string
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	Guid                 string
+	SourceUpdatedAt      sql.NullTime
+	EditedAt             sql.NullTime
+	Author               sql.NullString
+	Content              sql.NullString
+	Summary              sql.NullString
+	PublishedAtEstimated bool
+	FeedName             string
 }
 
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
@@ -108,6 +203,13 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Guid,
+			&i.SourceUpdatedAt,
+			&i.EditedAt,
+			&i.Author,
+			&i.Content,
+			&i.Summary,
+			&i.PublishedAtEstimated,
 			&i.FeedName,
 		); err != nil {
 			return nil, err
@@ -124,7 +226,7 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 }
 
 const getPostsForUserWithOffset = `-- name: GetPostsForUserWithOffset :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, feeds.name AS feed_name FROM posts
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.source_updated_at, posts.edited_at, posts.author, posts.content, posts.summary, posts.published_at_estimated, feeds.name AS feed_name FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 JOIN feeds ON posts.feed_id = feeds.id
 WHERE feed_follows.user_id = $1
@@ -139,15 +241,22 @@ type GetPostsForUserWithOffsetParams struct {
 }
 
 type GetPostsForUserWithOffsetRow struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
-	FeedName    string
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	Guid                 string
+	SourceUpdatedAt      sql.NullTime
+	EditedAt             sql.NullTime
+	Author               sql.NullString
+	Content              sql.NullString
+	Summary              sql.NullString
+	PublishedAtEstimated bool
+	FeedName             string
 }
 
 func (q *Queries) GetPostsForUserWithOffset(ctx context.Context, arg GetPostsForUserWithOffsetParams) ([]GetPostsForUserWithOffsetRow, error) {
@@ -168,6 +277,13 @@ func (q *Queries) GetPostsForUserWithOffset(ctx context.Context, arg GetPostsFor
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Guid,
+			&i.SourceUpdatedAt,
+			&i.EditedAt,
+			&i.Author,
+			&i.Content,
+			&i.Summary,
+			&i.PublishedAtEstimated,
 			&i.FeedName,
 		); err != nil {
 			return nil, err
@@ -182,3 +298,97 @@ func (q *Queries) GetPostsForUserWithOffset(ctx context.Context, arg GetPostsFor
 	}
 	return items, nil
 }
+
+const movePosts = `-- name: MovePosts :exec
+UPDATE posts
+SET feed_id = $1, updated_at = NOW()
+WHERE feed_id = $2
+  AND guid NOT IN (SELECT guid FROM posts WHERE feed_id = $1)
+`
+
+type MovePostsParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+// Moves posts from one feed to another, skipping any the target already has
+func (q *Queries) MovePosts(ctx context.Context, arg MovePostsParams) error {
+	_, err := q.db.ExecContext(ctx, movePosts, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
+
+const rekeyLegacyPost = `-- name: RekeyLegacyPost :execrows
+UPDATE posts
+SET guid = $1
+WHERE feed_id = $2
+  AND url = $3
+  AND guid = url
+  AND NOT EXISTS (SELECT 1 FROM posts WHERE feed_id = $2 AND guid = $1)
+`
+
+type RekeyLegacyPostParams struct {
+	Guid   string
+	FeedID uuid.UUID
+	Url    string
+}
+
+// Gives a post that migration 013 keyed by its link the item's real guid,
+// unless the feed already has a post under that guid
+func (q *Queries) RekeyLegacyPost(ctx context.Context, arg RekeyLegacyPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rekeyLegacyPost, arg.Guid, arg.FeedID, arg.Url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setPostContent = `-- name: SetPostContent :exec
+UPDATE posts
+SET content = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetPostContentParams struct {
+	ID      uuid.UUID
+	Content sql.NullString
+}
+
+func (q *Queries) SetPostContent(ctx context.Context, arg SetPostContentParams) error {
+	_, err := q.db.ExecContext(ctx, setPostContent, arg.ID, arg.Content)
+	return err
+}
+
+const updatePostContent = `-- name: UpdatePostContent :exec
+UPDATE posts
+SET title = $2, url = $3, description = $4, source_updated_at = $5, edited_at = $6, updated_at = $7, author = $8, content = $9, summary = $10
+WHERE id = $1
+`
+
+type UpdatePostContentParams struct {
+	ID              uuid.UUID
+	Title           string
+	Url             string
+	Description     sql.NullString
+	SourceUpdatedAt sql.NullTime
+	EditedAt        sql.NullTime
+	UpdatedAt       time.Time
+	Author          sql.NullString
+	Content         sql.NullString
+	Summary         sql.NullString
+}
+
+func (q *Queries) UpdatePostContent(ctx context.Context, arg UpdatePostContentParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostContent,
+		arg.ID,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.SourceUpdatedAt,
+		arg.EditedAt,
+		arg.UpdatedAt,
+		arg.Author,
+		arg.Content,
+		arg.Summary,
+	)
+	return err
+}
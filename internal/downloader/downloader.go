@@ -0,0 +1,348 @@
+package downloader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/Gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// DefaultMaxBytes caps a single download when no limit is configured
+const DefaultMaxBytes = 500 << 20
+
+// defaultBatch is how many of a feed's newest episodes are kept downloaded
+// when the feed has no retention count of its own
+const defaultBatch = 10
+
+// downloadTimeout bounds one enclosure download; an interrupted file is
+// resumed on the next pass
+const downloadTimeout = 30 * time.Minute
+
+// downloadIdle is how long the worker waits between passes over the feeds
+// with downloads turned on
+const downloadIdle = 5 * time.Minute
+
+// lockStaleAfter is how old a download's lock file must be before it is
+// taken to be left over from a crashed process. No download outlives
+// downloadTimeout, so a lock older than that has no owner.
+const lockStaleAfter = downloadTimeout + time.Minute
+
+// httpClient is used for enclosures instead of the feed fetcher's client,
+// whose per-host pacing and short request timeout suit feeds but not files
+// that take minutes to download
+var httpClient = &http.Client{Transport: newTransport()}
+
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = time.Minute
+	return transport
+}
+
+// errLocked is returned when another aggregator is already downloading the
+// enclosure
+var errLocked = errors.New("enclosure is being downloaded by another process")
+
+// errTooLarge marks enclosures that will never fit under the size limit, so
+// they are recorded as failed instead of retried every pass
+var errTooLarge = errors.New("enclosure exceeds the download size limit")
+
+// Downloader saves the audio and video enclosures of opted-in feeds to disk
+// for offline listening. Files live in Dir/<feed name>/, are written to a
+// .part file first and resumed with a Range request if interrupted.
+type Downloader struct {
+	Dir      string
+	MaxBytes int64
+}
+
+// Run downloads the enclosures of every feed with downloads turned on, pass
+// after pass, until ctx is done. It runs apart from polling, since a single
+// episode can take far longer than a feed's lease or an aggregator round.
+func (d *Downloader) Run(ctx context.Context, db *database.Queries) {
+	for ctx.Err() == nil {
+		feeds, err := db.GetDownloadFeeds(ctx)
+		if err != nil {
+			log.Printf("Couldn't get feeds to download: %v", err)
+		}
+		for _, feed := range feeds {
+			if ctx.Err() != nil {
+				return
+			}
+			downloaded, err := d.DownloadFeed(ctx, db, feed)
+			if err != nil {
+				log.Printf("Couldn't download enclosures for %s: %v", feed.Name, err)
+			}
+			if downloaded > 0 {
+				log.Printf("Feed %s: downloaded %d enclosures", feed.Name, downloaded)
+			}
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(downloadIdle):
+		}
+	}
+}
+
+// DownloadFeed fetches the feed's newest enclosures that aren't on disk yet,
+// then deletes downloads beyond the feed's retention count. It returns how
+// many files were downloaded.
+func (d *Downloader) DownloadFeed(ctx context.Context, db *database.Queries, feed database.Feed) (int, error) {
+	keep := int32(defaultBatch)
+	if feed.DownloadRetention.Valid && feed.DownloadRetention.Int32 > 0 {
+		keep = feed.DownloadRetention.Int32
+	}
+
+	enclosures, err := db.GetRecentFeedEnclosures(ctx, database.GetRecentFeedEnclosuresParams{
+		FeedID: feed.ID,
+		Limit:  keep,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("couldn't get enclosures: %w", err)
+	}
+
+	dir := filepath.Join(d.Dir, slug(feed.Name, feed.ID.String()))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("couldn't create download directory: %w", err)
+	}
+
+	downloaded := 0
+	for _, enclosure := range enclosures {
+		if enclosure.LocalPath.Valid || enclosure.DownloadError.Valid {
+			continue
+		}
+
+		localPath := filepath.Join(dir, fileName(enclosure))
+		err := d.download(ctx, enclosure, localPath)
+		if errors.Is(err, errTooLarge) {
+			log.Printf("Skipping %s: %v", enclosure.Url, err)
+			err = db.SetEnclosureDownloadError(ctx, database.SetEnclosureDownloadErrorParams{
+				ID:            enclosure.ID,
+				DownloadError: sql.NullString{String: err.Error(), Valid: true},
+			})
+			if err != nil {
+				return downloaded, err
+			}
+			continue
+		}
+		if errors.Is(err, errLocked) {
+			continue
+		}
+		if err != nil {
+			// Leave the .part file for the next pass to resume
+			log.Printf("Couldn't download %s: %v", enclosure.Url, err)
+			continue
+		}
+
+		now := time.Now().UTC()
+		err = db.SetEnclosureDownloaded(ctx, database.SetEnclosureDownloadedParams{
+			ID:           enclosure.ID,
+			LocalPath:    sql.NullString{String: localPath, Valid: true},
+			DownloadedAt: sql.NullTime{Time: now, Valid: true},
+		})
+		if err != nil {
+			return downloaded, err
+		}
+		downloaded++
+	}
+
+	if feed.DownloadRetention.Valid {
+		if err := d.prune(ctx, db, feed.ID, feed.DownloadRetention.Int32); err != nil {
+			return downloaded, err
+		}
+	}
+	return downloaded, nil
+}
+
+// prune deletes the files of downloads beyond the newest keep episodes
+func (d *Downloader) prune(ctx context.Context, db *database.Queries, feedID uuid.UUID, keep int32) error {
+	expired, err := db.GetExpiredEnclosureDownloads(ctx, database.GetExpiredEnclosureDownloadsParams{
+		FeedID: feedID,
+		Offset: keep,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get expired downloads: %w", err)
+	}
+
+	for _, enclosure := range expired {
+		err := os.Remove(enclosure.LocalPath.String)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Couldn't remove %s: %v", enclosure.LocalPath.String, err)
+			continue
+		}
+		if err := db.ClearEnclosureDownload(ctx, enclosure.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// download saves one enclosure to localPath, resuming a previous partial
+// download when the server supports range requests
+func (d *Downloader) download(ctx context.Context, enclosure database.GetRecentFeedEnclosuresRow, localPath string) error {
+	maxBytes := d.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if enclosure.Length.Valid && enclosure.Length.Int64 > maxBytes {
+		return errTooLarge
+	}
+
+	// Every aggregator runs a download worker, so the .part file is guarded
+	// by a lock
+	unlock, err := lock(localPath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	partPath := localPath + ".part"
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "GET", enclosure.Url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Gator RSS Reader/1.0; +https://github.com/user/gator)")
+	if offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		// Resuming where the .part file left off, unless the server sent a
+		// different range, which would corrupt the file if appended
+		if start, ok := rangeStart(response.Header.Get("Content-Range")); !ok || start != offset {
+			os.Remove(partPath)
+			return fmt.Errorf("server sent range %q when asked to resume at byte %d", response.Header.Get("Content-Range"), offset)
+		}
+	case http.StatusOK:
+		offset = 0 // The server ignored the range, so start over
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset > 0 {
+			return os.Rename(partPath, localPath) // Already complete
+		}
+		return fmt.Errorf("unexpected status %s", response.Status)
+	default:
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	if response.ContentLength > 0 && offset+response.ContentLength > maxBytes {
+		return errTooLarge
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(partPath, flags, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// Read one byte past the limit so an oversized body without a
+	// Content-Length is still caught
+	written, err := io.Copy(file, io.LimitReader(response.Body, maxBytes-offset+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if offset+written > maxBytes {
+		os.Remove(partPath)
+		return errTooLarge
+	}
+
+	return os.Rename(partPath, localPath)
+}
+
+// lock takes an exclusive lock file at path, clearing one left behind by a
+// crashed process first. It returns errLocked if another process holds it.
+func lock(path string) (unlock func(), err error) {
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockStaleAfter {
+		os.Remove(path)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, errLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	return func() { os.Remove(path) }, nil
+}
+
+// rangeStart returns the first byte of a "bytes start-end/size" Content-Range
+func rangeStart(contentRange string) (int64, bool) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(contentRange), "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(start), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// slug turns a title into a safe file name, falling back when nothing usable
+// is left
+func slug(s, fallback string) string {
+	s = strings.Trim(unsafeChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(s) > 80 {
+		s = strings.TrimRight(s[:80], "-")
+	}
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// fileName names a download after its post, with the enclosure ID to keep
+// posts with the same title apart and an extension from the URL or MIME type
+func fileName(enclosure database.GetRecentFeedEnclosuresRow) string {
+	ext := ""
+	if u, err := url.Parse(enclosure.Url); err == nil {
+		ext = path.Ext(u.Path)
+	}
+	if ext == "" || len(ext) > 5 {
+		ext = ""
+		if exts, _ := mime.ExtensionsByType(enclosure.MimeType.String); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	return fmt.Sprintf("%s-%s%s", slug(enclosure.PostTitle, "episode"), enclosure.ID.String()[:8], ext)
+}
@@ -0,0 +1,294 @@
+package readability
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/LFroesch/Gator/internal/rss"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// maxPageBytes caps how much of a page is read; article text is never
+// anywhere near this, but some pages inline megabytes of script
+const maxPageBytes = 5 << 20
+
+// minArticleChars is the least text an extracted article may have before it
+// is treated as a miss, like a cookie wall or a login page
+const minArticleChars = 250
+
+// httpClient shares the feed fetcher's per-host pacing and request timeout,
+// since articles mostly live on the same hosts as their feeds
+var httpClient = &http.Client{Transport: rss.Transport}
+
+// ErrNoArticle is returned when a page has no block of text that looks like
+// an article
+var ErrNoArticle = errors.New("couldn't find an article on the page")
+
+var (
+	// unlikelyCandidates match the class or id of page furniture that is
+	// removed before scoring, unless maybeCandidates match too
+	unlikelyCandidates = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|gdpr|header|menu|modal|nav|popup|promo|related|remark|replies|rss|share|shoutbox|sidebar|social|sponsor|subscribe|tags|tool|widget|ad-break|agegate|pagination|pager`)
+	maybeCandidates    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow|story|text|entry|post`)
+
+	positiveWeight = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeWeight = regexp.MustCompile(`(?i)hidden|banner|combx|comment|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget|byline|author|dateline`)
+)
+
+// removedTags never hold article text
+var removedTags = map[string]bool{
+	"aside": true, "button": true, "footer": true, "form": true, "header": true,
+	"iframe": true, "input": true, "nav": true, "noscript": true, "object": true,
+	"script": true, "select": true, "style": true, "svg": true, "textarea": true,
+}
+
+// Extract downloads pageURL and returns its main article body as sanitized
+// HTML, ready to store as a post's content
+func Extract(ctx context.Context, pageURL string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Gator RSS Reader/1.0; +https://github.com/user/gator)")
+	request.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return "", fmt.Errorf("unexpected status %s", response.Status)
+	}
+	contentType := response.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return "", fmt.Errorf("not a web page: %s", mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(response.Body, maxPageBytes), contentType)
+	if err != nil {
+		return "", err
+	}
+	doc, err := html.Parse(body)
+	if err != nil {
+		return "", err
+	}
+
+	article := Article(doc)
+	if article == nil || utf8.RuneCountInString(textContent(article)) < minArticleChars {
+		return "", ErrNoArticle
+	}
+	var rendered bytes.Buffer
+	if err := html.Render(&rendered, article); err != nil {
+		return "", err
+	}
+
+	// Relative links resolve against wherever redirects took us
+	return rss.SanitizeHTML(rendered.String(), response.Request.URL), nil
+}
+
+// Article finds the main body of a parsed page, readability style: page
+// furniture is dropped, every paragraph scores points for its length and
+// commas, those points flow up to the enclosing elements, and the best
+// scoring element wins along with any siblings that score nearly as well.
+// It returns nil when no paragraph is long enough to count. The document is
+// modified in the process.
+func Article(doc *html.Node) *html.Node {
+	removeUnlikely(doc)
+
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+	addScore := func(node *html.Node, score float64) {
+		if node == nil || node.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[node]; !ok {
+			scores[node] = initialScore(node)
+			candidates = append(candidates, node)
+		}
+		scores[node] += score
+	}
+
+	walk(doc, func(node *html.Node) {
+		switch node.Data {
+		case "p", "pre", "td", "blockquote":
+		default:
+			return
+		}
+		text := strings.TrimSpace(textContent(node))
+		length := utf8.RuneCountInString(text)
+		if length < 25 {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ","))
+		score += float64(min(length/100, 3))
+		addScore(node.Parent, score)
+		if node.Parent != nil {
+			addScore(node.Parent.Parent, score/2)
+		}
+	})
+
+	var best *html.Node
+	bestScore := 0.0
+	for _, node := range candidates {
+		scores[node] *= 1 - linkDensity(node)
+		if scores[node] > bestScore {
+			best, bestScore = node, scores[node]
+		}
+	}
+	if best == nil || best.Parent == nil {
+		return best
+	}
+
+	// Articles split across sibling blocks, like a lead paragraph outside
+	// the main container, pull their siblings in
+	threshold := max(10, bestScore*0.2)
+	article := &html.Node{Type: html.ElementNode, Data: "div"}
+	var siblings []*html.Node
+	for sibling := best.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		siblings = append(siblings, sibling)
+	}
+	for _, sibling := range siblings {
+		if sibling != best && !relatedSibling(sibling, scores, threshold) {
+			continue
+		}
+		sibling.Parent.RemoveChild(sibling)
+		article.AppendChild(sibling)
+	}
+	return article
+}
+
+// relatedSibling reports whether a sibling of the best candidate is part of
+// the article too
+func relatedSibling(node *html.Node, scores map[*html.Node]float64, threshold float64) bool {
+	if node.Type != html.ElementNode {
+		return false
+	}
+	if score, ok := scores[node]; ok && score >= threshold {
+		return true
+	}
+	if node.Data != "p" {
+		return false
+	}
+	text := strings.TrimSpace(textContent(node))
+	length := utf8.RuneCountInString(text)
+	density := linkDensity(node)
+	return (length > 80 && density < 0.25) ||
+		(length > 0 && density == 0 && strings.ContainsAny(text, ".!?"))
+}
+
+// removeUnlikely drops elements that never hold article text and those whose
+// class or id mark them as page furniture
+func removeUnlikely(doc *html.Node) {
+	var doomed []*html.Node
+	walk(doc, func(node *html.Node) {
+		if removedTags[node.Data] {
+			doomed = append(doomed, node)
+			return
+		}
+		switch node.Data {
+		case "html", "body", "article", "main":
+			return
+		}
+		match := attr(node, "class") + " " + attr(node, "id")
+		if unlikelyCandidates.MatchString(match) && !maybeCandidates.MatchString(match) {
+			doomed = append(doomed, node)
+		}
+	})
+	for _, node := range doomed {
+		if node.Parent != nil {
+			node.Parent.RemoveChild(node)
+		}
+	}
+}
+
+// initialScore favors elements that usually wrap articles and uses class and
+// id names as hints
+func initialScore(node *html.Node) float64 {
+	var score float64
+	switch node.Data {
+	case "article":
+		score = 10
+	case "div", "main", "section":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+
+	for _, name := range []string{attr(node, "class"), attr(node, "id")} {
+		if name == "" {
+			continue
+		}
+		if negativeWeight.MatchString(name) {
+			score -= 25
+		}
+		if positiveWeight.MatchString(name) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity is the share of an element's text that sits inside links;
+// navigation blocks are almost all links, articles hardly any
+func linkDensity(node *html.Node) float64 {
+	length := utf8.RuneCountInString(textContent(node))
+	if length == 0 {
+		return 0
+	}
+	linkLength := 0
+	walk(node, func(child *html.Node) {
+		if child.Data == "a" {
+			linkLength += utf8.RuneCountInString(textContent(child))
+		}
+	})
+	return min(float64(linkLength)/float64(length), 1)
+}
+
+// walk calls fn for every element below node, in document order
+func walk(node *html.Node, fn func(*html.Node)) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			fn(child)
+		}
+		walk(child, fn)
+	}
+}
+
+func textContent(node *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(node)
+	return sb.String()
+}
+
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package rss
+
+import (
+	"encoding/xml"
+	"html"
+	"strings"
+)
+
+// AtomText is an Atom text construct such as <content> or <summary>. With
+// type="xhtml" the body is inline markup rather than text, which a plain
+// string field would decode as empty, so it is written back out as HTML.
+type AtomText struct {
+	Type string
+	Body string
+}
+
+func (t *AtomText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == "type" {
+			t.Type = strings.TrimSpace(attr.Value)
+		}
+	}
+	if t.Type != "xhtml" {
+		return d.DecodeElement(&t.Body, &start)
+	}
+
+	// Namespace prefixes are dropped, so <xhtml:p> comes out as <p> for the
+	// sanitizer to recognize
+	var sb strings.Builder
+	depth := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := token.(type) {
+		case xml.StartElement:
+			depth++
+			sb.WriteString("<" + tok.Name.Local)
+			for _, attr := range tok.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				sb.WriteString(" " + attr.Name.Local + `="` + html.EscapeString(attr.Value) + `"`)
+			}
+			sb.WriteString(">")
+		case xml.EndElement:
+			if depth == 0 {
+				t.Body = strings.TrimSpace(sb.String())
+				return nil
+			}
+			depth--
+			// HTML reads </br> as a second <br>
+			if !voidTags[tok.Name.Local] {
+				sb.WriteString("</" + tok.Name.Local + ">")
+			}
+		case xml.CharData:
+			sb.WriteString(html.EscapeString(string(tok)))
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// toUTF8 applies the encoding a feed announces outside the document itself:
+// a byte order mark, or failing that the charset parameter of the HTTP
+// Content-Type. Both override the XML declaration, so decoded reports
+// whether the body was converted and the declaration must be ignored. With
+// neither, the body is returned as is and the declaration decides.
+func toUTF8(body []byte, contentType string) (utf8Body []byte, decoded bool, err error) {
+	var enc encoding.Encoding
+	switch {
+	case bytes.HasPrefix(body, []byte("\xef\xbb\xbf")):
+		return body[3:], true, nil
+	case bytes.HasPrefix(body, []byte("\xff\xfe")), bytes.HasPrefix(body, []byte("\xfe\xff")):
+		enc = unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	default:
+		_, params, _ := mime.ParseMediaType(contentType)
+		label := strings.TrimSpace(params["charset"])
+		if label == "" {
+			return body, false, nil
+		}
+		enc, err = htmlindex.Get(label)
+		if err != nil {
+			// An unknown charset in the header is more likely a typo than the
+			// truth, so let the document speak for itself
+			return body, false, nil
+		}
+	}
+
+	utf8Body, _, err = transform.Bytes(enc.NewDecoder(), body)
+	if err != nil {
+		return nil, false, fmt.Errorf("couldn't decode feed: %w", err)
+	}
+	return utf8Body, true, nil
+}
+
+// charsetReader is the xml.Decoder CharsetReader for documents whose XML
+// declaration names an encoding other than UTF-8. Labels are looked up the
+// way browsers do, so ISO-8859-1 decodes as its Windows-1252 superset.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q", label)
+	}
+	return transform.NewReader(input, enc.NewDecoder()), nil
+}
+
+// newXMLDecoder returns a decoder for body that converts it to UTF-8 as its
+// XML declaration asks, unless toUTF8 already did
+func newXMLDecoder(body []byte, decoded bool) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charsetReader
+	if decoded {
+		decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
+	}
+	return decoder
+}
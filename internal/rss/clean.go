@@ -0,0 +1,141 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// CleanDescription removes HTML tags and cleans up RSS feed descriptions.
+// Site-specific cleanup, like Reddit's "submitted by" lines, lives in the
+// extractors registered in sites.go.
+func CleanDescription(description string) string {
+	// First, unescape HTML entities so we can properly match HTML tags
+	unescaped := html.UnescapeString(description)
+
+	// Remove HTML comments
+	commentRegex := regexp.MustCompile(`<!--[\s\S]*?-->`)
+	unescaped = commentRegex.ReplaceAllString(unescaped, "")
+
+	// Remove HTML tags - more comprehensive regex
+	htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
+	cleaned := htmlTagRegex.ReplaceAllString(unescaped, "")
+
+	// Decode common HTML entities that might remain
+	cleaned = strings.ReplaceAll(cleaned, "&amp;", "&")
+	cleaned = strings.ReplaceAll(cleaned, "&lt;", "<")
+	cleaned = strings.ReplaceAll(cleaned, "&gt;", ">")
+	cleaned = strings.ReplaceAll(cleaned, "&quot;", "\"")
+	cleaned = strings.ReplaceAll(cleaned, "&#39;", "'")
+	cleaned = strings.ReplaceAll(cleaned, "&nbsp;", " ")
+
+	// Remove extra whitespace and newlines
+	cleaned = regexp.MustCompile(`\s+`).ReplaceAllString(cleaned, " ")
+	cleaned = strings.TrimSpace(cleaned)
+
+	return cleaned
+}
+
+// feedAutoClose are the HTML void elements a tolerant decoder closes when a
+// feed leaves them open. xml.HTMLAutoClose can't be used as is, since it
+// lists link, which is how RSS wraps every item's URL.
+var feedAutoClose = []string{"br", "hr", "img", "input", "wbr", "col", "area", "param"}
+
+// tolerate relaxes decoder for documents that aren't well-formed XML: it
+// accepts HTML entities like &nbsp;, bare ampersands in links, unclosed <br>
+// and <img> tags and mismatched end tags, leaving what it can't make sense of
+// as text rather than rewriting it. Unquoted attribute values need
+// quoteAttributes first.
+func tolerate(decoder *xml.Decoder) {
+	decoder.Strict = false
+	decoder.AutoClose = feedAutoClose
+	decoder.Entity = xml.HTMLEntity
+}
+
+// quoteAttributes puts quotes around unquoted attribute values, like
+// <link href=https://example.com/?a=1&b=2 />, which even a non-strict
+// xml.Decoder only reads up to the first "/". Text, CDATA sections and
+// comments are copied untouched.
+func quoteAttributes(body []byte) []byte {
+	out := make([]byte, 0, len(body))
+	for i := 0; i < len(body); {
+		switch {
+		case bytes.HasPrefix(body[i:], []byte("<![CDATA[")):
+			i = copyThrough(&out, body, i, "]]>")
+		case bytes.HasPrefix(body[i:], []byte("<!--")):
+			i = copyThrough(&out, body, i, "-->")
+		case body[i] == '<' && i+1 < len(body) && isLetter(body[i+1]):
+			i = quoteTag(&out, body, i)
+		default:
+			out = append(out, body[i])
+			i++
+		}
+	}
+	return out
+}
+
+// quoteTag copies the start tag at body[i:], quoting its unquoted attribute
+// values, and returns the index just past it
+func quoteTag(out *[]byte, body []byte, i int) int {
+	for i < len(body) {
+		c := body[i]
+		switch {
+		case c == '>':
+			*out = append(*out, c)
+			return i + 1
+		case c == '"' || c == '\'':
+			end := bytes.IndexByte(body[i+1:], c)
+			if end < 0 {
+				*out = append(*out, body[i:]...)
+				return len(body)
+			}
+			*out = append(*out, body[i:i+end+2]...)
+			i += end + 2
+		case c == '=':
+			*out = append(*out, c)
+			i++
+			for i < len(body) && isSpace(body[i]) {
+				*out = append(*out, body[i])
+				i++
+			}
+			if i >= len(body) || body[i] == '"' || body[i] == '\'' {
+				continue
+			}
+			// The value runs to whitespace or the end of the tag, where a
+			// "/" right before ">" closes the element instead
+			start := i
+			for i < len(body) && !isSpace(body[i]) && body[i] != '>' && !bytes.HasPrefix(body[i:], []byte("/>")) {
+				i++
+			}
+			*out = append(*out, '"')
+			*out = append(*out, bytes.ReplaceAll(body[start:i], []byte(`"`), []byte("&quot;"))...)
+			*out = append(*out, '"')
+		default:
+			*out = append(*out, c)
+			i++
+		}
+	}
+	return i
+}
+
+// copyThrough copies body[i:] up to and including the next end, or to the
+// end of body, and returns the index just past it
+func copyThrough(out *[]byte, body []byte, i int, end string) int {
+	n := bytes.Index(body[i:], []byte(end))
+	if n < 0 {
+		*out = append(*out, body[i:]...)
+		return len(body)
+	}
+	*out = append(*out, body[i:i+n+len(end)]...)
+	return i + n + len(end)
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
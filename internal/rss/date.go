@@ -0,0 +1,109 @@
+package rss
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dateLayouts are tried in order once ParsePubDate has normalized the
+// string: weekday and commas dropped, named zones and Z turned into offsets,
+// and offsets written without a colon. Go's "2" accepts single-digit days
+// and "06" two-digit years.
+var dateLayouts = []string{
+	// RFC 822 and the many variants of it in RSS pubDates
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2 2006 15:04:05",
+	"Jan 2 2006",
+
+	// ISO 8601 as used by Atom, dc:date and JSON Feed
+	"2006-01-02T15:04:05.999999999-0700",
+	"2006-01-02T15:04-0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// zoneOffsets maps the zone names that show up in pubDates to their
+// offsets, since time.Parse only understands the local zone's abbreviation
+var zoneOffsets = map[string]string{
+	"UT": "+0000", "UTC": "+0000", "GMT": "+0000", "Z": "+0000",
+	"EST": "-0500", "EDT": "-0400",
+	"CST": "-0600", "CDT": "-0500",
+	"MST": "-0700", "MDT": "-0600",
+	"PST": "-0800", "PDT": "-0700",
+	"AKST": "-0900", "AKDT": "-0800",
+	"HST": "-1000",
+	"BST": "+0100", "IST": "+0530",
+	"WET": "+0000", "WEST": "+0100",
+	"CET": "+0100", "CEST": "+0200",
+	"EET": "+0200", "EEST": "+0300",
+	"MSK": "+0300",
+	"JST": "+0900", "KST": "+0900",
+	"AEST": "+1000", "AEDT": "+1100",
+	"NZST": "+1200", "NZDT": "+1300",
+}
+
+var (
+	weekdayPrefix = regexp.MustCompile(`(?i)^(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s*`)
+	zoneComment   = regexp.MustCompile(`\s*\([^)]*\)$`)
+	trailingZone  = regexp.MustCompile(`\s([A-Za-z]{1,5})$`)
+	colonOffset   = regexp.MustCompile(`([+-]\d\d):(\d\d)$`)
+	spaceOffset   = regexp.MustCompile(`(\d)([+-]\d{4})$`)
+)
+
+// ParsePubDate converts an item's PubDate into a nullable timestamp. Beyond
+// the RFC 822 and ISO 8601 layouts the specs ask for, it copes with what
+// feeds actually send: two-digit years, missing seconds, named zones like
+// EST, single-digit days, wrong or missing weekdays and fractional seconds.
+// Dates without a zone are taken as UTC. Unknown formats come back NULL.
+func ParsePubDate(pubDate string) sql.NullTime {
+	s := strings.Join(strings.Fields(pubDate), " ")
+	if s == "" {
+		return sql.NullTime{}
+	}
+
+	// The exact standard layouts first, so well-formed dates skip the
+	// normalizing below
+	for _, layout := range []string{time.RFC1123Z, time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return sql.NullTime{Time: t, Valid: true}
+		}
+	}
+
+	s = weekdayPrefix.ReplaceAllString(s, "")
+	s = zoneComment.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, ",", "")
+	if match := trailingZone.FindStringSubmatch(s); match != nil {
+		if offset, ok := zoneOffsets[strings.ToUpper(match[1])]; ok {
+			s = s[:len(s)-len(match[1])] + offset
+		}
+	}
+	if strings.HasSuffix(s, "Z") {
+		s = strings.TrimSuffix(s, "Z") + "+0000"
+	}
+	s = colonOffset.ReplaceAllString(s, "$1$2")
+	// "2024-01-02 10:00:00+0000" has no space before the offset
+	if !strings.Contains(s, "T") {
+		s = spaceOffset.ReplaceAllString(s, "$1 $2")
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return sql.NullTime{Time: t, Valid: true}
+		}
+	}
+	return sql.NullTime{}
+}
@@ -0,0 +1,221 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Candidate is a feed found by Discover. Feed is set when Discover already
+// had to download and parse the feed to find it.
+type Candidate struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+
+	Feed *RSSFeed `json:"-"`
+}
+
+// ErrNotFeed is returned for documents that aren't feeds: by Parse and Fetch
+// when the root element is none of rss, rdf:RDF or feed, and by Probe when a
+// document parses but has neither a title nor any items
+var ErrNotFeed = errors.New("not an RSS, Atom or JSON feed")
+
+// feedLinkTypes are the <link type> values that advertise a feed
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/rdf+xml":   true,
+}
+
+// commonFeedPaths are probed, in order, when a page doesn't advertise any
+// feed itself
+var commonFeedPaths = []string{"/feed", "/rss.xml", "/atom.xml", "/feed.xml", "/index.xml", "/rss", "/feed.json"}
+
+// Discover finds the feeds behind pageURL. A URL that already is a feed
+// comes back as the only candidate. For an HTML page, the feeds it
+// advertises with <link rel="alternate"> are returned; if there are none,
+// the site's common feed paths are probed and the first that parses wins.
+// An empty result with a nil error means the page has no feed we can find;
+// probes that failed for other reasons, like timeouts, are returned as the
+// error.
+func Discover(ctx context.Context, pageURL string) ([]Candidate, error) {
+	request, err := newRequest(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	client, movedTo := redirectTrackingClient()
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		response.Body.Close()
+		return nil, &StatusError{StatusCode: response.StatusCode}
+	}
+	// The response is closed before probing, since it holds one of the
+	// host's request slots and the probes go to the same host
+	body, truncated, err := readBody(response)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := response.Header.Get("Content-Type")
+	if !isHTML(contentType, body) {
+		feed, _, err := parse(body, contentType, truncated)
+		if err != nil {
+			return nil, err
+		}
+		// A feed that has permanently moved is stored under its new address
+		feedURL := pageURL
+		if *movedTo != "" {
+			feedURL = *movedTo
+		}
+		return []Candidate{{URL: feedURL, Title: feed.Channel.Title, Feed: feed}}, nil
+	}
+
+	// Relative links resolve against wherever redirects took us
+	base := response.Request.URL
+	if candidates := feedLinks(body, base); len(candidates) > 0 {
+		return candidates, nil
+	}
+
+	// Paths that don't exist or aren't feeds are expected; anything else,
+	// like a timeout, means the site may well have a feed we couldn't see
+	var probeErrs []error
+	for _, path := range commonFeedPaths {
+		probeURL := base.ResolveReference(&url.URL{Path: path}).String()
+		result, err := Fetch(ctx, probeURL, Validators{})
+		var statusErr *StatusError
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrNotFeed), errors.As(err, &statusErr) && statusErr.StatusCode < 500:
+			continue
+		default:
+			probeErrs = append(probeErrs, fmt.Errorf("couldn't probe %s: %w", probeURL, err))
+			continue
+		}
+		// Single-page apps answer every path with their HTML shell, which
+		// parses as an empty feed, so keep looking past those
+		if result.Feed == nil || isEmpty(result.Feed) {
+			continue
+		}
+		return []Candidate{{URL: probeURL, Title: result.Feed.Channel.Title, Feed: result.Feed}}, nil
+	}
+	return nil, errors.Join(probeErrs...)
+}
+
+// Probe test-fetches a candidate, unless Discover already did, and checks
+// that it really is a feed. The parsed feed carries the channel metadata to
+// store with it.
+func Probe(ctx context.Context, candidate Candidate) (*RSSFeed, error) {
+	feed := candidate.Feed
+	if feed == nil {
+		result, err := Fetch(ctx, candidate.URL, Validators{})
+		if err != nil {
+			return nil, err
+		}
+		feed = result.Feed
+	}
+
+	if isEmpty(feed) {
+		return nil, ErrNotFeed
+	}
+	return feed, nil
+}
+
+// isEmpty reports whether a parsed document has neither a title nor any
+// items, as an arbitrary XML file or a web page does
+func isEmpty(feed *RSSFeed) bool {
+	return feed.Channel.Title == "" && len(feed.Channel.Item) == 0
+}
+
+// isHTML reports whether a response is a web page rather than a feed. The
+// body wins over the Content-Type, since plenty of servers label their
+// feeds text/html.
+func isHTML(contentType string, body []byte) bool {
+	head := bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	head = bytes.ToLower(head)
+
+	switch {
+	case bytes.HasPrefix(head, []byte("<!doctype html")), bytes.HasPrefix(head, []byte("<html")):
+		return true
+	case bytes.HasPrefix(head, []byte("<?xml")):
+		// XHTML pages carry an XML declaration too
+		return bytes.Contains(head, []byte("<!doctype html")) || bytes.Contains(head, []byte("<html"))
+	case bytes.HasPrefix(head, []byte("<rss")), bytes.HasPrefix(head, []byte("<feed")), bytes.HasPrefix(head, []byte("{")):
+		return false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// feedLinks collects the <link rel="alternate"> feeds a page advertises,
+// honoring <base href> for relative URLs
+func feedLinks(body []byte, base *url.URL) []Candidate {
+	var candidates []Candidate
+	seen := make(map[string]bool)
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return candidates
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			attrs := make(map[string]string)
+			for _, attr := range token.Attr {
+				attrs[strings.ToLower(attr.Key)] = strings.TrimSpace(attr.Val)
+			}
+
+			switch token.Data {
+			case "base":
+				if href, err := url.Parse(attrs["href"]); err == nil && attrs["href"] != "" {
+					base = base.ResolveReference(href)
+				}
+			case "link":
+				feedType := strings.ToLower(attrs["type"])
+				if !hasToken(attrs["rel"], "alternate") || !feedLinkTypes[feedType] || attrs["href"] == "" {
+					continue
+				}
+				href, err := url.Parse(attrs["href"])
+				if err != nil {
+					continue
+				}
+				feedURL := base.ResolveReference(href).String()
+				if seen[feedURL] {
+					continue
+				}
+				seen[feedURL] = true
+				candidates = append(candidates, Candidate{
+					URL:   feedURL,
+					Title: attrs["title"],
+					Type:  feedType,
+				})
+			}
+		}
+	}
+}
+
+// hasToken reports whether a space-separated attribute like rel contains
+// token, ignoring case
+func hasToken(list, token string) bool {
+	for _, field := range strings.Fields(list) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
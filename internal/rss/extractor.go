@@ -0,0 +1,73 @@
+package rss
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Extractor turns one kind of site's item markup into the plain-text
+// description shown in post lists. Items no registered extractor claims get
+// the generic CleanDescription.
+type Extractor interface {
+	// Match reports whether the extractor handles an item from a feed whose
+	// site lives at host
+	Match(host string, item *RSSItem) bool
+	// Describe returns the item's plain-text description given its raw,
+	// entity-decoded HTML one
+	Describe(item *RSSItem, raw string) string
+}
+
+var extractors []Extractor
+
+// RegisterExtractor adds e to the registry. Extractors are tried in the
+// order they were registered and the first match wins.
+func RegisterExtractor(e Extractor) {
+	extractors = append(extractors, e)
+}
+
+// SiteExtractor is an Extractor for the sites in Hosts, subdomains included,
+// and for any item whose raw description matches Pattern. Pattern covers
+// sites that are syndicated through other hosts, like Hacker News through
+// hnrss.org.
+type SiteExtractor struct {
+	Hosts   []string
+	Pattern *regexp.Regexp
+	Clean   func(item *RSSItem, raw string) string
+}
+
+func (e *SiteExtractor) Match(host string, item *RSSItem) bool {
+	for _, h := range e.Hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return e.Pattern != nil && e.Pattern.MatchString(item.Description)
+}
+
+func (e *SiteExtractor) Describe(item *RSSItem, raw string) string {
+	return e.Clean(item, raw)
+}
+
+// describe builds an item's plain-text description with the first extractor
+// that claims it
+func describe(siteLink string, item *RSSItem) string {
+	raw := item.Description
+	host := hostOf(siteLink)
+	for _, e := range extractors {
+		if e.Match(host, item) {
+			return e.Describe(item, raw)
+		}
+	}
+	return CleanDescription(raw)
+}
+
+// hostOf returns a URL's lowercased host without a leading www., or "" if it
+// doesn't parse
+func hostOf(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
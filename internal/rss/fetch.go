@@ -0,0 +1,427 @@
+package rss
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Validators are the HTTP cache validators remembered from a previous fetch
+// of the same feed.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// Result is the outcome of a single Fetch. When the server answers 304 Not
+// Modified, NotModified is set and Feed is nil. MaxAge is how long the server
+// says the response stays fresh, from Cache-Control or Expires.
+type Result struct {
+	Feed        *RSSFeed
+	NotModified bool
+	Validators  Validators
+	MaxAge      time.Duration
+
+	StatusCode int
+	Bytes      int64
+
+	// MovedTo is set when the feed was only reached through permanent
+	// redirects (301/308) and is the URL to fetch it from from now on
+	MovedTo string
+
+	// Truncated is set when the document went past the configured Limits,
+	// so Feed only holds the items that fit
+	Truncated bool
+}
+
+// StatusError is returned when the server answers with a non-success status.
+// RetryAfter is how long a 429 or 503 response asked us to stay away.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Fetch downloads feedURL and parses it as RSS, Atom or JSON Feed. Item titles
+// are unescaped and descriptions cut down to plain text by the site's
+// extractor, so the CLI and the API server see exactly the same text for a
+// post. Cached validators are sent
+// as a conditional GET so unchanged feeds cost a single 304 round trip.
+//
+// Once the server has responded, the returned Result is non-nil even when err
+// is set, so callers can still log the status code and size of a failed fetch.
+func Fetch(ctx context.Context, feedURL string, cached Validators) (*Result, error) {
+	request, err := newRequest(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	if cached.ETag != "" {
+		request.Header.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		request.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	client, movedTo := redirectTrackingClient()
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	result := &Result{
+		StatusCode: response.StatusCode,
+		MaxAge:     cacheMaxAge(response.Header),
+	}
+	if response.StatusCode == http.StatusNotModified || (response.StatusCode >= 200 && response.StatusCode <= 299) {
+		result.MovedTo = *movedTo
+	}
+
+	if response.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		result.Validators = cached
+		return result, nil
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		statusErr := &StatusError{StatusCode: response.StatusCode}
+		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable {
+			statusErr.RetryAfter = retryAfter(response.Header, time.Now())
+		}
+		return result, statusErr
+	}
+
+	body, truncated, err := readBody(response)
+	result.Bytes = int64(len(body))
+	if err != nil {
+		return result, err
+	}
+
+	feed, truncated, err := parse(body, response.Header.Get("Content-Type"), truncated)
+	if err != nil {
+		return result, err
+	}
+
+	result.Feed = feed
+	result.Truncated = truncated
+	result.Validators = Validators{
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+	}
+	return result, nil
+}
+
+// httpClient has no overall timeout of its own: Transport times each request
+// once it gets its turn with the host
+var httpClient = &http.Client{Transport: Transport}
+
+// redirectTrackingClient returns a client for a single request that records
+// where a chain of permanent redirects ends. Once a temporary redirect shows
+// up, later hops no longer say anything about where the feed lives.
+func redirectTrackingClient() (*http.Client, *string) {
+	client := *httpClient
+	movedTo := new(string)
+	permanent := true
+	client.CheckRedirect = func(request *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		status := request.Response.StatusCode
+		if permanent && (status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect) {
+			*movedTo = request.URL.String()
+		} else {
+			permanent = false
+		}
+		return nil
+	}
+	return &client, movedTo
+}
+
+// newRequest builds a GET for a feed or website with the headers every
+// request we make carries
+func newRequest(ctx context.Context, pageURL string) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set headers that make the request appear more legitimate to avoid being blocked
+	request.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Gator RSS Reader/1.0; +https://github.com/user/gator)")
+	request.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/rdf+xml, application/xml, text/xml, */*")
+	request.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	request.Header.Set("Accept-Encoding", "gzip, deflate")
+	request.Header.Set("Connection", "keep-alive")
+	return request, nil
+}
+
+// readBody reads a response body up to the configured byte limit, handling
+// gzipped responses, and reports whether the body went on past the limit.
+// The limit applies after decompression, so a small gzip bomb can't blow up.
+func readBody(response *http.Response) (body []byte, truncated bool, err error) {
+	var reader io.Reader = response.Body
+	if response.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return nil, false, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	return readLimited(reader, limits.MaxBytes)
+}
+
+// Parse decodes a raw feed document. JSON Feeds are recognized by their
+// Content-Type or by sniffing the body; XML documents are told apart by
+// their root element: rdf:RDF for RSS 1.0, feed for Atom and rss for RSS
+// 2.0. Any other document, like a web page, fails with ErrNotFeed. Only the
+// first items up to the configured limit are kept.
+func Parse(body []byte, contentType string) (*RSSFeed, error) {
+	feed, _, err := parse(body, contentType, false)
+	return feed, err
+}
+
+// parse is Parse for a body that may have been cut off at the byte limit, in
+// which case the items that arrived whole are kept. truncated reports
+// whether the document was cut short by either limit.
+func parse(body []byte, contentType string, bodyTruncated bool) (feed *RSSFeed, truncated bool, err error) {
+	if isJSONFeed(contentType, body) {
+		if bodyTruncated {
+			return nil, true, fmt.Errorf("JSON Feed is larger than the %d byte limit", limits.MaxBytes)
+		}
+		var jsonFeed JSONFeed
+		if err := json.Unmarshal(body, &jsonFeed); err != nil {
+			return nil, false, fmt.Errorf("JSON Feed parsing error: %w", err)
+		}
+		converted := jsonFeedToRSS(jsonFeed)
+		if len(converted.Channel.Item) > limits.MaxItems {
+			converted.Channel.Item = converted.Channel.Item[:limits.MaxItems]
+			truncated = true
+		}
+		feed = &converted
+	} else {
+		feed, truncated, err = parseXML(body, contentType, bodyTruncated)
+		if err != nil {
+			return nil, truncated, err
+		}
+		// Only XML feeds escape their HTML twice
+		for i := range feed.Channel.Item {
+			feed.Channel.Item[i].Description = unescapeDoubled(feed.Channel.Item[i].Description)
+			feed.Channel.Item[i].Content = unescapeDoubled(feed.Channel.Item[i].Content)
+		}
+	}
+	normalize(feed)
+	return feed, truncated, nil
+}
+
+// normalize unescapes and trims what the parsers leave raw, and fills in the
+// fields every format shares, like an item's sanitized HTML and plain-text
+// description
+func normalize(feed *RSSFeed) {
+	// Unescape HTML entities and clean up descriptions
+	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
+	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
+	feed.Channel.Link = strings.TrimSpace(feed.Channel.Link)
+	feed.Channel.Language = strings.TrimSpace(feed.Channel.Language)
+	feed.Channel.Icon = channelIcon(feed)
+	for i := range feed.Channel.Item {
+		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
+		feed.Channel.Item[i].GUID = strings.TrimSpace(feed.Channel.Item[i].GUID)
+		if feed.Channel.Item[i].PubDate == "" {
+			feed.Channel.Item[i].PubDate = feed.Channel.Item[i].DCDate
+		}
+		if feed.Channel.Item[i].Author == "" {
+			feed.Channel.Item[i].Author = feed.Channel.Item[i].DCCreator
+		}
+		feed.Channel.Item[i].Author = strings.TrimSpace(feed.Channel.Item[i].Author)
+		feed.Channel.Item[i].Enclosures = collectEnclosures(feed.Channel.Item[i].Enclosures, feed.Channel.Item[i].Media)
+
+		// The plain-text description comes from the summary, or from the
+		// full body for feeds that only carry one
+		base := itemBase(feed.Channel.Link, feed.Channel.Item[i].Link)
+		feed.Channel.Item[i].SummaryHTML = SanitizeHTML(feed.Channel.Item[i].Description, base)
+		feed.Channel.Item[i].ContentHTML = SanitizeHTML(feed.Channel.Item[i].Content, base)
+		if feed.Channel.Item[i].Description == "" && feed.Channel.Item[i].Text != "" {
+			// Unescaping and stripping tags would eat anything in plain
+			// text that looks like markup, like "a <b> & c"
+			feed.Channel.Item[i].Description = strings.Join(strings.Fields(feed.Channel.Item[i].Text), " ")
+			continue
+		}
+		if feed.Channel.Item[i].Description == "" {
+			feed.Channel.Item[i].Description = feed.Channel.Item[i].Content
+		}
+		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+		feed.Channel.Item[i].Description = describe(feed.Channel.Link, &feed.Channel.Item[i])
+	}
+}
+
+// parseXML decodes an RSS 1.0, RSS 2.0 or Atom document in a single pass,
+// stopping at the item limit. The character encoding comes from a byte order
+// mark, the Content-Type header or the XML declaration, in that order. A
+// document cut off at the byte limit keeps the items that arrived whole.
+func parseXML(body []byte, contentType string, bodyTruncated bool) (feed *RSSFeed, truncated bool, err error) {
+	body, decoded, err := toUTF8(body, contentType)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Anything else, like a web page now served where the feed used to be,
+	// must not pass for an empty feed
+	root := rootElement(body, decoded)
+	if root != "rss" && root != "RDF" && root != "feed" {
+		return nil, false, ErrNotFeed
+	}
+
+	feed, truncated, err = decodeXML(body, decoded, root, false)
+	if err != nil {
+		// Documents that aren't well-formed get a second, tolerant pass. A
+		// body cut off at the byte limit always ends in an error, so there
+		// the pass that got further wins.
+		tolerantFeed, tolerantTruncated, tolerantErr := decodeXML(body, decoded, root, true)
+		if !bodyTruncated || len(tolerantFeed.Channel.Item) > len(feed.Channel.Item) {
+			feed, truncated, err = tolerantFeed, tolerantTruncated, tolerantErr
+		}
+	}
+	if bodyTruncated {
+		// Running out of body is expected, and everything decoded before
+		// that is still good
+		return feed, true, nil
+	}
+	if err != nil {
+		return nil, truncated, fmt.Errorf("XML parsing error: %w", err)
+	}
+	return feed, truncated, nil
+}
+
+// decodeXML decodes a UTF-8 or self-describing XML feed with the given root
+// element into the RSS structure, keeping at most the configured number of
+// items. The feed is returned even with an error, holding whatever was
+// decoded before it. tolerant reads it the way tolerate describes.
+func decodeXML(body []byte, decoded bool, root string, tolerant bool) (*RSSFeed, bool, error) {
+	if tolerant {
+		body = quoteAttributes(body)
+	}
+	newDecoder := func() *xml.Decoder {
+		decoder := newXMLDecoder(body, decoded)
+		if tolerant {
+			tolerate(decoder)
+		}
+		return decoder
+	}
+
+	switch root {
+	case "RDF":
+		var rdfFeed RDFFeed
+		truncated, err := decodeCapped(newDecoder(), &rdfFeed, limits.MaxItems)
+		feed := rdfToRSS(rdfFeed)
+		return &feed, truncated, err
+	case "feed":
+		var atomFeed AtomFeed
+		truncated, err := decodeCapped(newDecoder(), &atomFeed, limits.MaxItems)
+		feed := atomToRSS(atomFeed)
+		return &feed, truncated, err
+	default: // rss
+		var feed RSSFeed
+		truncated, err := decodeCapped(newDecoder(), &feed, limits.MaxItems)
+		return &feed, truncated, err
+	}
+}
+
+// channelIcon picks the feed's artwork, falling back to the site's favicon
+func channelIcon(feed *RSSFeed) string {
+	for _, icon := range []string{feed.Channel.Icon, feed.Channel.Image.URL, feed.Channel.ITunesImage.Href} {
+		if icon = strings.TrimSpace(icon); icon != "" {
+			return icon
+		}
+	}
+
+	site, err := url.Parse(feed.Channel.Link)
+	if err != nil || site.Host == "" || (site.Scheme != "http" && site.Scheme != "https") {
+		return ""
+	}
+	return site.Scheme + "://" + site.Host + "/favicon.ico"
+}
+
+// atomToRSS converts an Atom document into the RSS structure
+func atomToRSS(atomFeed AtomFeed) RSSFeed {
+	var feed RSSFeed
+	feed.Channel.Title = atomFeed.Title
+	feed.Channel.Description = atomFeed.Description
+	feed.Channel.Language = atomFeed.Language
+	feed.Channel.Icon = atomFeed.Icon
+	if feed.Channel.Icon == "" {
+		feed.Channel.Icon = atomFeed.Logo
+	}
+	feed.Channel.UpdatePeriod = atomFeed.UpdatePeriod
+	feed.Channel.UpdateFrequency = atomFeed.UpdateFrequency
+
+	// Find the alternate link
+	for _, link := range atomFeed.Link {
+		if link.Rel == "alternate" || link.Rel == "" {
+			feed.Channel.Link = link.Href
+			break
+		}
+	}
+
+	// Convert entries to items
+	feed.Channel.Item = make([]RSSItem, len(atomFeed.Entry))
+	for i, entry := range atomFeed.Entry {
+		feed.Channel.Item[i].Title = entry.Title
+		feed.Channel.Item[i].GUID = entry.ID
+
+		// Find the entry link and any enclosure links
+		for _, link := range entry.Link {
+			if (link.Rel == "alternate" || link.Rel == "") && feed.Channel.Item[i].Link == "" {
+				feed.Channel.Item[i].Link = link.Href
+			}
+			if link.Rel == "enclosure" {
+				feed.Channel.Item[i].Enclosures = append(feed.Channel.Item[i].Enclosures, Enclosure{
+					URL:      link.Href,
+					MimeType: link.Type,
+					Length:   parseLength(link.Length),
+				})
+			}
+		}
+		feed.Channel.Item[i].Media = entry.Media
+
+		// Summary, or the media description YouTube uses instead, is the
+		// teaser; content is the full body
+		feed.Channel.Item[i].Description = entry.Summary.Body
+		if feed.Channel.Item[i].Description == "" {
+			feed.Channel.Item[i].Description = entry.MediaDescription
+		}
+		for _, group := range entry.MediaGroup {
+			if feed.Channel.Item[i].Description == "" {
+				feed.Channel.Item[i].Description = group.Description
+			}
+		}
+		feed.Channel.Item[i].Content = entry.Content.Body
+
+		// Use published or updated for date
+		feed.Channel.Item[i].Updated = entry.Updated
+
+		var authors []string
+		for _, author := range entry.Author {
+			if author.Name != "" {
+				authors = append(authors, author.Name)
+			}
+		}
+		feed.Channel.Item[i].Author = strings.Join(authors, ", ")
+		if entry.Published != "" {
+			feed.Channel.Item[i].PubDate = entry.Published
+		} else {
+			feed.Channel.Item[i].PubDate = entry.Updated
+		}
+	}
+	return feed
+}
@@ -0,0 +1,21 @@
+package rss
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+)
+
+// Key returns the identifier used to deduplicate an item within its feed: the
+// <guid> or Atom <id> when present, otherwise the link, otherwise a hash of
+// the title and description so link-less items don't all collide.
+func (item RSSItem) Key() string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	if item.Link != "" {
+		return item.Link
+	}
+	// Must stay in sync with the backfill in sql/schema/013_post_guids.sql
+	sum := md5.Sum([]byte(item.Title + "\n" + item.Description))
+	return "md5:" + hex.EncodeToString(sum[:])
+}
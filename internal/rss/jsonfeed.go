@@ -0,0 +1,153 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"mime"
+	"strings"
+)
+
+// JSONFeed represents a JSON Feed document (https://jsonfeed.org/version/1.1).
+// Version 1 feeds are read too: they use a single author instead of authors.
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Language    string         `json:"language"`
+	Icon        string         `json:"icon"`
+	Favicon     string         `json:"favicon"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            jsonFeedID           `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Image         string               `json:"image"`
+	Authors       []JSONFeedAuthor     `json:"authors"`
+	Author        *JSONFeedAuthor      `json:"author"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type JSONFeedAttachment struct {
+	URL               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	Title             string  `json:"title"`
+	SizeInBytes       int64   `json:"size_in_bytes"`
+	DurationInSeconds float64 `json:"duration_in_seconds"`
+}
+
+// jsonFeedID is an item id. The spec says it is a string, but plenty of
+// generators emit bare numbers, which would otherwise fail the whole feed.
+type jsonFeedID string
+
+func (id *jsonFeedID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = jsonFeedID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = jsonFeedID(n.String())
+	return nil
+}
+
+// isJSONFeed reports whether a response is a JSON Feed, going by the
+// Content-Type first and falling back to sniffing the body, since many
+// servers label JSON Feeds as text/plain or application/octet-stream.
+func isJSONFeed(contentType string, body []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/feed+json", "application/json":
+		return true
+	}
+
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{' && bytes.Contains(body, []byte("jsonfeed.org/version"))
+}
+
+// jsonFeedToRSS converts a JSON Feed document into the RSS structure
+func jsonFeedToRSS(jsonFeed JSONFeed) RSSFeed {
+	var feed RSSFeed
+	feed.Channel.Title = jsonFeed.Title
+	feed.Channel.Link = jsonFeed.HomePageURL
+	feed.Channel.Description = jsonFeed.Description
+	feed.Channel.Language = jsonFeed.Language
+	feed.Channel.Icon = jsonFeed.Icon
+	if feed.Channel.Icon == "" {
+		feed.Channel.Icon = jsonFeed.Favicon
+	}
+
+	feed.Channel.Item = make([]RSSItem, len(jsonFeed.Items))
+	for i, entry := range jsonFeed.Items {
+		item := &feed.Channel.Item[i]
+		item.Title = entry.Title
+		item.GUID = string(entry.ID)
+		item.PubDate = entry.DatePublished
+		item.Updated = entry.DateModified
+
+		// Link posts point at the article elsewhere; attachment-only items
+		// (podcasts) fall back to the media file itself
+		item.Link = entry.URL
+		if item.Link == "" {
+			item.Link = entry.ExternalURL
+		}
+		if item.Link == "" && len(entry.Attachments) > 0 {
+			item.Link = entry.Attachments[0].URL
+		}
+
+		// Plain text content is escaped so it isn't mistaken for markup
+		item.Description = entry.Summary
+		item.Content = entry.ContentHTML
+		if item.Content == "" && entry.ContentText != "" {
+			item.Content = html.EscapeString(entry.ContentText)
+			item.Text = entry.ContentText
+		}
+
+		for _, attachment := range entry.Attachments {
+			item.Enclosures = append(item.Enclosures, Enclosure{
+				URL:             attachment.URL,
+				MimeType:        attachment.MimeType,
+				Length:          attachment.SizeInBytes,
+				DurationSeconds: int(attachment.DurationInSeconds),
+			})
+		}
+		if entry.Image != "" {
+			item.MediaThumbnail = []MediaThumbnail{{URL: entry.Image}}
+		}
+
+		authors := entry.Authors
+		if len(authors) == 0 && entry.Author != nil {
+			authors = []JSONFeedAuthor{*entry.Author}
+		}
+		var names []string
+		for _, author := range authors {
+			if author.Name != "" {
+				names = append(names, author.Name)
+			}
+		}
+		item.Author = strings.Join(names, ", ")
+
+		if item.PubDate == "" && item.Updated != "" {
+			item.PubDate = item.Updated
+		}
+	}
+	return feed
+}
@@ -0,0 +1,101 @@
+package rss
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Limits caps how much of a single feed document is read and parsed, so one
+// huge or malicious feed can't exhaust the aggregator's or API server's
+// memory. A document past either limit is cut short and the fetch reports
+// it as truncated.
+type Limits struct {
+	// MaxBytes is the most of a (decompressed) response body that is read
+	MaxBytes int64
+	// MaxItems is the most items or entries that are decoded
+	MaxItems int
+}
+
+// DefaultLimits apply when no limit is configured
+var DefaultLimits = Limits{
+	MaxBytes: 10 << 20,
+	MaxItems: 500,
+}
+
+var limits = DefaultLimits
+
+// SetLimits changes the limits for every later Fetch and Discover. Zero
+// fields keep the defaults. It is meant to be called once at startup.
+func SetLimits(l Limits) {
+	if l.MaxBytes <= 0 {
+		l.MaxBytes = DefaultLimits.MaxBytes
+	}
+	if l.MaxItems <= 0 {
+		l.MaxItems = DefaultLimits.MaxItems
+	}
+	limits = l
+}
+
+// readLimited reads r up to max bytes and reports whether there was more
+func readLimited(r io.Reader, max int64) (body []byte, truncated bool, err error) {
+	body, err = io.ReadAll(io.LimitReader(r, max+1))
+	if int64(len(body)) > max {
+		return body[:max], true, err
+	}
+	return body, false, err
+}
+
+// itemCap is an xml.TokenReader that ends the document cleanly in place of
+// the first item or entry past max, by closing the elements still open. A
+// Decode through it gets the items before that and reads no further.
+type itemCap struct {
+	tokens    xml.TokenReader
+	max       int
+	items     int
+	open      []xml.Name
+	truncated bool
+}
+
+func (c *itemCap) Token() (xml.Token, error) {
+	if c.truncated {
+		if len(c.open) == 0 {
+			return nil, io.EOF
+		}
+		name := c.open[len(c.open)-1]
+		c.open = c.open[:len(c.open)-1]
+		return xml.EndElement{Name: name}, nil
+	}
+
+	token, err := c.tokens.Token()
+	if err != nil {
+		return token, err
+	}
+	switch t := token.(type) {
+	case xml.StartElement:
+		// Items sit under <channel> in RSS 2.0 and directly under the root
+		// in RSS 1.0 and Atom
+		if len(c.open) <= 2 && (t.Name.Local == "item" || t.Name.Local == "entry") {
+			c.items++
+			if c.items > c.max {
+				c.truncated = true
+				return c.Token()
+			}
+		}
+		c.open = append(c.open, t.Name)
+	case xml.EndElement:
+		if len(c.open) > 0 {
+			c.open = c.open[:len(c.open)-1]
+		}
+	}
+	return token, nil
+}
+
+// decodeCapped decodes the document read by decoder into v, stopping after
+// maxItems items, and reports whether any were left out
+func decodeCapped(decoder *xml.Decoder, v any, maxItems int) (truncated bool, err error) {
+	capped := &itemCap{tokens: decoder, max: maxItems}
+	outer := xml.NewTokenDecoder(capped)
+	outer.Strict = decoder.Strict
+	err = outer.Decode(v)
+	return capped.truncated, err
+}
@@ -0,0 +1,153 @@
+package rss
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Enclosure is a media file attached to an item, such as a podcast episode,
+// a video or a piece of artwork. Zero values mean the feed didn't say.
+type Enclosure struct {
+	URL             string
+	MimeType        string
+	Length          int64
+	DurationSeconds int
+	ThumbnailURL    string
+}
+
+// Media holds the raw enclosure markup an item can carry: RSS <enclosure>,
+// Media RSS (used by YouTube and most video sites) and the iTunes podcast
+// namespace. Numeric attributes are read as strings because feeds regularly
+// leave them empty or put junk in them, which would otherwise fail the whole
+// document.
+type Media struct {
+	RSSEnclosure   []RSSEnclosure   `xml:"enclosure"`
+	MediaContent   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnail []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaGroup     []MediaGroup     `xml:"http://search.yahoo.com/mrss/ group"`
+	ITunesDuration string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesImage    ITunesImage      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type MediaContent struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Medium   string `xml:"medium,attr"`
+	FileSize string `xml:"fileSize,attr"`
+	Duration string `xml:"duration,attr"`
+}
+
+type MediaThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+type MediaGroup struct {
+	Content   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnail []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	// YouTube keeps the video's description in its media:group
+	Description string `xml:"http://search.yahoo.com/mrss/ description"`
+}
+
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// collectEnclosures merges enclosures the format converters already found
+// (Atom enclosure links, JSON Feed attachments) with the item's media markup.
+// Enclosures are de-duplicated by URL, and item-level artwork and iTunes
+// durations fill in whatever the enclosure itself left out. An item with
+// artwork but no media file still gets an enclosure so the UI can show it.
+func collectEnclosures(found []Enclosure, media Media) []Enclosure {
+	for _, enclosure := range media.RSSEnclosure {
+		found = append(found, Enclosure{
+			URL:      enclosure.URL,
+			MimeType: enclosure.Type,
+			Length:   parseLength(enclosure.Length),
+		})
+	}
+
+	contents := media.MediaContent
+	thumbnails := media.MediaThumbnail
+	for _, group := range media.MediaGroup {
+		contents = append(contents, group.Content...)
+		thumbnails = append(thumbnails, group.Thumbnail...)
+	}
+	for _, content := range contents {
+		enclosure := Enclosure{
+			URL:             content.URL,
+			MimeType:        content.Type,
+			Length:          parseLength(content.FileSize),
+			DurationSeconds: parseDuration(content.Duration),
+		}
+		if content.Medium == "image" || strings.HasPrefix(content.Type, "image/") {
+			enclosure.ThumbnailURL = content.URL
+		}
+		found = append(found, enclosure)
+	}
+
+	thumbnail := strings.TrimSpace(media.ITunesImage.Href)
+	for _, t := range thumbnails {
+		if url := strings.TrimSpace(t.URL); url != "" {
+			thumbnail = url
+			break
+		}
+	}
+	duration := parseDuration(media.ITunesDuration)
+
+	var enclosures []Enclosure
+	seen := make(map[string]bool)
+	for _, enclosure := range found {
+		enclosure.URL = strings.TrimSpace(enclosure.URL)
+		if enclosure.URL == "" || seen[enclosure.URL] {
+			continue
+		}
+		seen[enclosure.URL] = true
+
+		if enclosure.DurationSeconds == 0 {
+			enclosure.DurationSeconds = duration
+		}
+		if enclosure.ThumbnailURL == "" {
+			enclosure.ThumbnailURL = thumbnail
+		}
+		enclosures = append(enclosures, enclosure)
+	}
+
+	if len(enclosures) == 0 && thumbnail != "" {
+		enclosures = append(enclosures, Enclosure{URL: thumbnail, ThumbnailURL: thumbnail})
+	}
+	return enclosures
+}
+
+// parseLength reads a byte count, treating anything unparsable as unknown
+func parseLength(s string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// parseDuration reads an itunes:duration or media:content duration, which
+// may be plain seconds ("3600", "3600.5") or clock time ("1:00:00", "59:30")
+func parseDuration(s string) int {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+
+	seconds := 0.0
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return int(seconds)
+}
@@ -0,0 +1,188 @@
+package rss
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// hostRate is how many requests per second a single host gets once its
+	// burst is used up
+	hostRate = 1.0
+	// hostBurst is how many requests a host that has been left alone for a
+	// while gets back to back
+	hostBurst = 3.0
+	// hostConcurrency is how many requests to one host may be in flight at
+	// once, counting until the response body is closed
+	hostConcurrency = 2
+	// requestTimeout bounds a request from the moment it gets its turn to
+	// the moment its body is closed. Time spent queueing for a busy host
+	// doesn't count, so a long queue doesn't turn into timeouts.
+	requestTimeout = 30 * time.Second
+	// hostIdleTTL is how long a host's limiter is kept after its last
+	// request. By then its bucket is full again, so forgetting it changes
+	// nothing, and a long-running aggregator doesn't keep every host it has
+	// ever contacted.
+	hostIdleTTL = 10 * time.Minute
+)
+
+// Transport is the http.RoundTripper behind every request for a feed or an
+// article page. It paces requests per host with a token bucket and caps how
+// many run against a host at once, so a concurrent aggregator or a user
+// refreshing all their feeds doesn't hammer reddit.com or youtube.com back to
+// back. Requests wait their turn or until their context is done.
+var Transport http.RoundTripper = &politeTransport{
+	base:  http.DefaultTransport,
+	hosts: make(map[string]*hostLimiter),
+}
+
+type politeTransport struct {
+	base http.RoundTripper
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+	swept time.Time
+}
+
+// hostLimiter is the token bucket and in-flight slots for one host
+type hostLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	slots chan struct{}
+}
+
+func (t *politeTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	host := t.host(strings.ToLower(request.URL.Hostname()))
+	if err := host.acquire(request.Context()); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), requestTimeout)
+	response, err := t.base.RoundTrip(request.WithContext(ctx))
+	if err != nil {
+		cancel()
+		host.release()
+		return nil, err
+	}
+	response.Body = &releasingBody{ReadCloser: response.Body, release: func() {
+		cancel()
+		host.release()
+	}}
+	return response, nil
+}
+
+// host returns the limiter for name, creating it with a full bucket
+func (t *politeTransport) host(name string) *hostLimiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now := time.Now(); now.Sub(t.swept) > hostIdleTTL {
+		t.evictIdle(now)
+		t.swept = now
+	}
+	h, ok := t.hosts[name]
+	if !ok {
+		h = &hostLimiter{
+			tokens: hostBurst,
+			last:   time.Now(),
+			slots:  make(chan struct{}, hostConcurrency),
+		}
+		t.hosts[name] = h
+	}
+	return h
+}
+
+// evictIdle forgets hosts with nothing in flight and no request for
+// hostIdleTTL. The caller holds t.mu.
+func (t *politeTransport) evictIdle(now time.Time) {
+	for name, h := range t.hosts {
+		h.mu.Lock()
+		idle := len(h.slots) == 0 && now.Sub(h.last) > hostIdleTTL
+		h.mu.Unlock()
+		if idle {
+			delete(t.hosts, name)
+		}
+	}
+}
+
+// acquire waits for a free slot and then for a token
+func (h *hostLimiter) acquire(ctx context.Context) error {
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	for {
+		wait := h.take()
+		if wait == 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			h.release()
+			return ctx.Err()
+		}
+	}
+}
+
+// take spends a token if there is one, or returns how long until there is
+func (h *hostLimiter) take() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	h.tokens = min(hostBurst, h.tokens+now.Sub(h.last).Seconds()*hostRate)
+	h.last = now
+	if h.tokens >= 1 {
+		h.tokens--
+		return 0
+	}
+	return time.Duration((1 - h.tokens) / hostRate * float64(time.Second))
+}
+
+func (h *hostLimiter) release() {
+	<-h.slots
+}
+
+// releasingBody ends the request and gives the host's slot back once the
+// caller is done reading
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// retryAfter reads a Retry-After header, given either in seconds or as an
+// HTTP date, and returns 0 if there is none or it is already past
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
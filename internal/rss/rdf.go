@@ -0,0 +1,76 @@
+package rss
+
+import (
+	"encoding/xml"
+)
+
+// RDFFeed represents an RSS 1.0 document. Unlike RSS 2.0, the items are
+// siblings of <channel> under <rdf:RDF> rather than children of it.
+type RDFFeed struct {
+	XMLName xml.Name `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# RDF"`
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Language    string `xml:"http://purl.org/dc/elements/1.1/ language"`
+
+		UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+	} `xml:"channel"`
+	Image struct {
+		URL string `xml:"url"`
+	} `xml:"image"`
+	Item []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+// rootElement returns the local name of the document's root element, or ""
+// if it can't be found
+func rootElement(body []byte, decoded bool) string {
+	decoder := newXMLDecoder(body, decoded)
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+// rdfToRSS converts an RSS 1.0 document into the RSS structure
+func rdfToRSS(rdfFeed RDFFeed) RSSFeed {
+	var feed RSSFeed
+	feed.Channel.Title = rdfFeed.Channel.Title
+	feed.Channel.Link = rdfFeed.Channel.Link
+	feed.Channel.Description = rdfFeed.Channel.Description
+	feed.Channel.Language = rdfFeed.Channel.Language
+	feed.Channel.Icon = rdfFeed.Image.URL
+	feed.Channel.UpdatePeriod = rdfFeed.Channel.UpdatePeriod
+	feed.Channel.UpdateFrequency = rdfFeed.Channel.UpdateFrequency
+
+	feed.Channel.Item = make([]RSSItem, len(rdfFeed.Item))
+	for i, entry := range rdfFeed.Item {
+		feed.Channel.Item[i] = RSSItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Description,
+			Content:     entry.Content,
+			PubDate:     entry.Date,
+			GUID:        entry.About,
+			Author:      entry.Creator,
+		}
+	}
+	return feed
+}
@@ -0,0 +1,81 @@
+package rss
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RefreshInterval returns how often the publisher says the feed changes,
+// from RSS <ttl> or the syndication module's sy:updatePeriod and
+// sy:updateFrequency. Zero means the feed gives no hint; values that aren't
+// plain numbers, like "60 min", are ignored.
+func (f *RSSFeed) RefreshInterval() time.Duration {
+	if ttl := parseCount(f.Channel.TTL); ttl > 0 {
+		return time.Duration(ttl) * time.Minute
+	}
+
+	if f.Channel.UpdatePeriod == "" {
+		return 0
+	}
+
+	var period time.Duration
+	switch strings.ToLower(strings.TrimSpace(f.Channel.UpdatePeriod)) {
+	case "hourly":
+		period = time.Hour
+	case "daily":
+		period = 24 * time.Hour
+	case "weekly":
+		period = 7 * 24 * time.Hour
+	case "monthly":
+		period = 30 * 24 * time.Hour
+	case "yearly":
+		period = 365 * 24 * time.Hour
+	default:
+		return 0
+	}
+
+	// updateFrequency is how many times per period the feed updates
+	frequency := parseCount(f.Channel.UpdateFrequency)
+	if frequency < 1 {
+		frequency = 1
+	}
+	return period / time.Duration(frequency)
+}
+
+// parseCount reads a whole number from a feed, treating anything unparsable
+// as missing
+func parseCount(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// cacheMaxAge reads the freshness lifetime from Cache-Control max-age, falling
+// back to Expires relative to Date
+func cacheMaxAge(header http.Header) time.Duration {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.TrimSpace(strings.ToLower(directive))
+		if value, ok := strings.CutPrefix(directive, "max-age="); ok {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	now := time.Now()
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		now = date
+	}
+	if expires.After(now) {
+		return expires.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,227 @@
+package rss
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags maps each element SanitizeHTML keeps to the attributes it may
+// carry. Elements not listed here are unwrapped: their tags go, their text
+// stays.
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": {"cite"},
+	"br":         nil,
+	"caption":    nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        nil,
+	"kbd":        nil,
+	"li":         nil,
+	"mark":       nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"q":          {"cite"},
+	"s":          nil,
+	"small":      nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// droppedTags are removed together with everything inside them, since their
+// contents are code, styling or form controls rather than article text
+var droppedTags = map[string]bool{
+	"button": true, "embed": true, "form": true, "iframe": true, "input": true,
+	"link": true, "math": true, "meta": true, "noscript": true, "object": true,
+	"script": true, "select": true, "style": true, "svg": true, "template": true,
+	"textarea": true, "title": true,
+}
+
+// voidTags have no closing tag
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// urlAttrs hold URLs, which are resolved against the item's link and limited
+// to safe schemes
+var urlAttrs = map[string]bool{"href": true, "src": true, "cite": true}
+
+// SanitizeHTML reduces an item's HTML body to an allowlisted subset that is
+// safe to render in the browser: links, paragraphs, lists, code, tables and
+// images. Scripts, styles, event handlers and inline CSS are removed, and
+// relative links and image sources are resolved against base so they still
+// work away from the publisher's site.
+func SanitizeHTML(raw string, base *url.URL) string {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(raw), body)
+	if err != nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, node := range nodes {
+		sanitizeNode(&sb, node, base)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// unescapeDoubled undoes the second round of escaping some XML feeds apply to
+// their HTML, which leaves entity-encoded tags after XML decoding. Plain text
+// that was escaped on purpose, like JSON Feed's content_text, must never go
+// through it, or its angle brackets would turn back into markup.
+func unescapeDoubled(raw string) string {
+	if !strings.Contains(raw, "<") && strings.Contains(raw, "&lt;") {
+		return html.UnescapeString(raw)
+	}
+	return raw
+}
+
+func sanitizeNode(sb *strings.Builder, node *html.Node, base *url.URL) {
+	switch node.Type {
+	case html.TextNode:
+		sb.WriteString(html.EscapeString(node.Data))
+		return
+	case html.ElementNode:
+	default:
+		// Comments and doctypes
+		return
+	}
+
+	tag := strings.ToLower(node.Data)
+	if droppedTags[tag] {
+		return
+	}
+	allowed, ok := allowedTags[tag]
+	if !ok {
+		sanitizeChildren(sb, node, base)
+		return
+	}
+
+	attrs := sanitizeAttrs(node.Attr, allowed, base)
+	if tag == "img" && !hasAttr(attrs, "src") {
+		return
+	}
+
+	sb.WriteString("<" + tag)
+	for _, attr := range attrs {
+		sb.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if tag == "a" && hasAttr(attrs, "href") {
+		sb.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	sb.WriteString(">")
+	if voidTags[tag] {
+		return
+	}
+	sanitizeChildren(sb, node, base)
+	sb.WriteString("</" + tag + ">")
+}
+
+func sanitizeChildren(sb *strings.Builder, node *html.Node, base *url.URL) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		sanitizeNode(sb, child, base)
+	}
+}
+
+// sanitizeAttrs keeps the allowed attributes, dropping URLs that don't
+// resolve to http, https or mailto
+func sanitizeAttrs(attrs []html.Attribute, allowed []string, base *url.URL) []html.Attribute {
+	var kept []html.Attribute
+	for _, attr := range attrs {
+		key := strings.ToLower(attr.Key)
+		if attr.Namespace != "" || !slices.Contains(allowed, key) {
+			continue
+		}
+		val := strings.TrimSpace(attr.Val)
+		if urlAttrs[key] {
+			resolved, ok := safeURL(val, base)
+			if !ok {
+				continue
+			}
+			val = resolved
+		}
+		kept = append(kept, html.Attribute{Key: key, Val: val})
+	}
+	return kept
+}
+
+// safeURL resolves ref against base and reports whether the result uses a
+// scheme that can't run script
+func safeURL(ref string, base *url.URL) (string, bool) {
+	u, err := url.Parse(ref)
+	if err != nil || ref == "" {
+		return "", false
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return u.String(), true
+	case "":
+		// Still relative, because there was no base to resolve against
+		return u.String(), true
+	}
+	return "", false
+}
+
+// itemBase returns the URL an item's relative links are resolved against:
+// its own link, made absolute with the channel's link if need be
+func itemBase(channelLink, itemLink string) *url.URL {
+	var base *url.URL
+	if u, err := url.Parse(strings.TrimSpace(channelLink)); err == nil && u.IsAbs() {
+		base = u
+	}
+	u, err := url.Parse(strings.TrimSpace(itemLink))
+	if err != nil || itemLink == "" {
+		return base
+	}
+	if base != nil {
+		return base.ResolveReference(u)
+	}
+	if u.IsAbs() {
+		return u
+	}
+	return nil
+}
+
+func hasAttr(attrs []html.Attribute, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package rss
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The site extractors shipped with Gator. A new site only needs a
+// SiteExtractor registered here.
+func init() {
+	RegisterExtractor(&SiteExtractor{
+		Hosts:   []string{"news.ycombinator.com"},
+		Pattern: regexp.MustCompile(`(?s)Article URL:.*Comments URL:`),
+		Clean:   extractHackerNewsDescription,
+	})
+	RegisterExtractor(&SiteExtractor{
+		Hosts: []string{"reddit.com"},
+		Clean: extractRedditDescription,
+	})
+	RegisterExtractor(&SiteExtractor{
+		Hosts: []string{"youtube.com"},
+		Clean: extractYouTubeDescription,
+	})
+	RegisterExtractor(&SiteExtractor{
+		Hosts: []string{"github.com"},
+		Clean: extractGitHubReleaseDescription,
+	})
+	RegisterExtractor(&SiteExtractor{
+		Hosts: []string{"arxiv.org"},
+		Clean: extractArxivDescription,
+	})
+	RegisterExtractor(&SiteExtractor{
+		Hosts: []string{"lobste.rs"},
+		Clean: extractLobstersDescription,
+	})
+}
+
+// extractHackerNewsDescription extracts meaningful content from Hacker News style descriptions
+func extractHackerNewsDescription(item *RSSItem, description string) string {
+	// For Hacker News style feeds, we'll create a more meaningful description
+	// Extract the title from the link if possible, or provide a summary
+
+	// Remove HTML tags first
+	htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
+	plainText := htmlTagRegex.ReplaceAllString(description, "")
+
+	// Extract article URL
+	articleURLRegex := regexp.MustCompile(`Article URL:\s*([^\s]+)`)
+	matches := articleURLRegex.FindStringSubmatch(plainText)
+
+	var result string
+	if len(matches) > 1 {
+		articleURL := matches[1]
+		// Try to extract a meaningful description from the URL
+		if strings.Contains(articleURL, "github.com") {
+			result = "GitHub repository: " + extractGitHubRepoInfo(articleURL)
+		} else if strings.Contains(articleURL, "arxiv.org") {
+			result = "Research paper from arXiv"
+		} else if strings.Contains(articleURL, "youtube.com") || strings.Contains(articleURL, "youtu.be") {
+			result = "YouTube video"
+		} else {
+			// Extract domain for a generic description
+			domainRegex := regexp.MustCompile(`https?://([^/]+)`)
+			domainMatches := domainRegex.FindStringSubmatch(articleURL)
+			if len(domainMatches) > 1 {
+				domain := domainMatches[1]
+				result = fmt.Sprintf("Article from %s", domain)
+			} else {
+				result = "External article"
+			}
+		}
+	} else {
+		result = "Hacker News discussion"
+	}
+
+	// Add points and comments info if available
+	pointsRegex := regexp.MustCompile(`Points:\s*(\d+)`)
+	commentsRegex := regexp.MustCompile(`#\s*Comments:\s*(\d+)`)
+
+	pointsMatches := pointsRegex.FindStringSubmatch(plainText)
+	commentsMatches := commentsRegex.FindStringSubmatch(plainText)
+
+	if len(pointsMatches) > 1 && len(commentsMatches) > 1 {
+		result += fmt.Sprintf(" • %s points, %s comments", pointsMatches[1], commentsMatches[1])
+	}
+
+	return result
+}
+
+// extractGitHubRepoInfo extracts repository name from GitHub URL
+func extractGitHubRepoInfo(url string) string {
+	repoRegex := regexp.MustCompile(`github\.com/([^/]+)/([^/?]+)`)
+	matches := repoRegex.FindStringSubmatch(url)
+	if len(matches) > 2 {
+		return fmt.Sprintf("%s/%s", matches[1], matches[2])
+	}
+	return url
+}
+
+var (
+	redditSubmitted = regexp.MustCompile(`\s*submitted by\s+/u/[\w-]+\s*`)
+	redditLinks     = regexp.MustCompile(`\s*\[link\]\s*\[comments\]\s*$`)
+)
+
+// extractRedditDescription drops the "submitted by /u/name [link] [comments]"
+// footer Reddit appends to every post
+func extractRedditDescription(item *RSSItem, description string) string {
+	description = strings.ReplaceAll(description, "<!-- SC_OFF -->", "")
+	description = strings.ReplaceAll(description, "<!-- SC_ON -->", "")
+	cleaned := CleanDescription(description)
+	cleaned = redditSubmitted.ReplaceAllString(cleaned, " ")
+	cleaned = redditLinks.ReplaceAllString(cleaned, "")
+	return strings.TrimSpace(cleaned)
+}
+
+// extractYouTubeDescription keeps the first paragraph of a video's
+// description; the rest is usually chapters, sponsor links and social media
+func extractYouTubeDescription(item *RSSItem, description string) string {
+	for _, paragraph := range strings.Split(description, "\n\n") {
+		if cleaned := CleanDescription(paragraph); cleaned != "" {
+			return cleaned
+		}
+	}
+	return "YouTube video"
+}
+
+var (
+	githubBlockTags   = regexp.MustCompile(`(?i)</?(p|li|ul|ol|h[1-6]|div|br)\b[^>]*>`)
+	githubContributor = regexp.MustCompile(`\s+by @[\w-]+ in https://github\.com/\S+`)
+	githubChangelog   = regexp.MustCompile(`\s*Full Changelog:\s*\S+`)
+)
+
+// extractGitHubReleaseDescription trims the per-line attributions and the
+// changelog link out of generated release notes, and names the repository
+// for releases without notes
+func extractGitHubReleaseDescription(item *RSSItem, description string) string {
+	if !strings.Contains(item.Link, "/releases/") {
+		return CleanDescription(description)
+	}
+	// Release notes are mostly lists, so keep their items apart
+	cleaned := CleanDescription(githubBlockTags.ReplaceAllString(description, " "))
+	cleaned = githubContributor.ReplaceAllString(cleaned, "")
+	cleaned = strings.TrimSpace(githubChangelog.ReplaceAllString(cleaned, ""))
+	if cleaned == "" {
+		return "New release of " + extractGitHubRepoInfo(item.Link)
+	}
+	return cleaned
+}
+
+var arxivHeader = regexp.MustCompile(`^arXiv:\S+\s+Announce Type:\s*\S+\s*(Abstract:\s*)?`)
+
+// extractArxivDescription strips the "arXiv:2401.01234v1 Announce Type: new
+// Abstract:" header so the description starts with the abstract itself
+func extractArxivDescription(item *RSSItem, description string) string {
+	return arxivHeader.ReplaceAllString(CleanDescription(description), "")
+}
+
+var lobstersComments = regexp.MustCompile(`(?s)<p>\s*<a [^>]*>Comments</a>\s*</p>\s*$`)
+
+// extractLobstersDescription drops the trailing comments link Lobsters adds
+// to every story; link posts have nothing else, so they name the article's
+// site instead
+func extractLobstersDescription(item *RSSItem, description string) string {
+	cleaned := CleanDescription(lobstersComments.ReplaceAllString(strings.TrimSpace(description), ""))
+	if cleaned != "" {
+		return cleaned
+	}
+	if host := hostOf(item.Link); host != "" && host != "lobste.rs" {
+		return fmt.Sprintf("Article from %s", host)
+	}
+	return "Lobsters discussion"
+}
@@ -0,0 +1,104 @@
+package rss
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTolerantCorpus parses feeds that aren't well-formed XML, each reduced
+// from a kind of breakage seen in the wild, and checks that every item link
+// comes through exactly as the publisher meant it
+func TestTolerantCorpus(t *testing.T) {
+	tests := []struct {
+		file  string
+		title string
+		links []string
+	}{
+		{
+			file:  "bare-ampersands.xml",
+			title: "Deals & Steals",
+			links: []string{
+				"https://shop.example.com/product?id=1042&utm_source=rss&utm_medium=feed",
+				"https://shop.example.com/product?id=1043&utm_source=rss",
+			},
+		},
+		{
+			file:  "html-entities.xml",
+			title: "Café Notes — a blog",
+			links: []string{"https://cafe.example.org/2024/05/best-espresso/?lang=fr&ref=feed"},
+		},
+		{
+			file:  "unclosed-void-tags.xml",
+			title: "Town Council News",
+			links: []string{
+				"http://council.example.gov/news/view.php?item=77&cat=3",
+				"http://council.example.gov/news/view.php?item=78&cat=3",
+			},
+		},
+		{
+			file:  "cdata-and-raw-markup.xml",
+			title: "Dev Log",
+			links: []string{
+				"https://devlog.example.net/post?slug=release-2-1&src=rss",
+				"https://devlog.example.net/post?slug=broken&src=rss",
+			},
+		},
+		{
+			file:  "atom-unquoted-attributes.xml",
+			title: "Photo Stream & Notes",
+			links: []string{
+				"https://photos.example.com/view?id=9&size=large",
+				"https://photos.example.com/view?id=10&size=large",
+			},
+		},
+		{
+			file:  "latin1-entities.xml",
+			title: "Journal du quartier",
+			links: []string{"http://quartier.example.fr/article.php?id=12&rubrique=3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", "broken", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			feed, err := Parse(body, "application/xml")
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if feed.Channel.Title != tt.title {
+				t.Errorf("title = %q, want %q", feed.Channel.Title, tt.title)
+			}
+			if len(feed.Channel.Item) != len(tt.links) {
+				t.Fatalf("got %d items, want %d", len(feed.Channel.Item), len(tt.links))
+			}
+			for i, want := range tt.links {
+				if got := feed.Channel.Item[i].Link; got != want {
+					t.Errorf("item %d link = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestQuoteAttributes checks that only unquoted attribute values change
+func TestQuoteAttributes(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`<link href=https://ex.com/?q=1&r=2 />`, `<link href="https://ex.com/?q=1&r=2" />`},
+		{`<link rel=alternate href=https://ex.com/a/>`, `<link rel="alternate" href="https://ex.com/a"/>`},
+		{`<link href="https://ex.com/?q=1&r=2"/>`, `<link href="https://ex.com/?q=1&r=2"/>`},
+		{`<p>a=b c</p>`, `<p>a=b c</p>`},
+		{`<d><![CDATA[<a href=x>]]></d>`, `<d><![CDATA[<a href=x>]]></d>`},
+		{`<!-- <a href=x> -->`, `<!-- <a href=x> -->`},
+	}
+	for _, tt := range tests {
+		if got := string(quoteAttributes([]byte(tt.in))); got != tt.want {
+			t.Errorf("quoteAttributes(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
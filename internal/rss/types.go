@@ -0,0 +1,96 @@
+package rss
+
+// RSSFeed is the normalized feed shape returned by Fetch. Atom feeds are
+// converted into it so callers only ever deal with one structure.
+type RSSFeed struct {
+	Channel struct {
+		Title string `xml:"title"`
+		// atom:link (rel="self") must be declared before link, or it would
+		// overwrite the site link it shares a local name with
+		AtomLink    []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+		Link        string     `xml:"link"`
+		Description string     `xml:"description"`
+		Language    string     `xml:"language"`
+		Item        []RSSItem  `xml:"item"`
+
+		// Channel artwork; itunes:image is declared first for the same reason
+		// as atom:link. Icon is filled in by Parse for every format.
+		ITunesImage ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+		Image       struct {
+			URL string `xml:"url"`
+		} `xml:"image"`
+		Icon string `xml:"-"`
+
+		// Publisher hints for how often the feed changes, read as strings
+		// for the same reason as Media's numeric attributes
+		TTL             string `xml:"ttl"`
+		UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+	} `xml:"channel"`
+}
+
+type RSSItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string `xml:"pubDate"`
+	GUID        string `xml:"guid"`
+	Updated     string `xml:"updated"`
+	Author      string `xml:"author"`
+
+	// Dublin Core fallbacks, common in WordPress and RSS 1.0 derived feeds
+	DCDate    string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	DCCreator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+
+	Media
+	// Enclosures is filled in by Parse from the media markup above and from
+	// Atom enclosure links and JSON Feed attachments
+	Enclosures []Enclosure `xml:"-"`
+	// SummaryHTML and ContentHTML are the item's teaser and full body run
+	// through SanitizeHTML, while Description is cut down to plain text
+	SummaryHTML string `xml:"-"`
+	ContentHTML string `xml:"-"`
+	// Text is the body of a JSON Feed item that only has content_text. It
+	// is already plain text, so it becomes the description as it is.
+	Text string `xml:"-"`
+}
+
+// AtomFeed represents an Atom feed structure (used by Reddit, YouTube, etc.)
+type AtomFeed struct {
+	Title       string      `xml:"title"`
+	Link        []AtomLink  `xml:"link"`
+	Description string      `xml:"subtitle"`
+	Language    string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Icon        string      `xml:"icon"`
+	Logo        string      `xml:"logo"`
+	Entry       []AtomEntry `xml:"entry"`
+
+	UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+	UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type AtomEntry struct {
+	ID               string       `xml:"id"`
+	Title            string       `xml:"title"`
+	Link             []AtomLink   `xml:"link"`
+	Content          AtomText     `xml:"content"`
+	Summary          AtomText     `xml:"summary"`
+	Published        string       `xml:"published"`
+	Updated          string       `xml:"updated"`
+	Author           []AtomPerson `xml:"author"`
+	MediaDescription string       `xml:"http://search.yahoo.com/mrss/ description"`
+
+	Media
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
@@ -0,0 +1,76 @@
+package scraper
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/Gator/internal/database"
+	"github.com/LFroesch/Gator/internal/rss"
+	"github.com/google/uuid"
+)
+
+// AddFeed stores a feed that rss.Probe has already fetched, along with its
+// channel metadata. An empty name defaults to the channel title.
+func AddFeed(ctx context.Context, db *database.Queries, userID uuid.UUID, name, feedURL string, parsed *rss.RSSFeed) (database.Feed, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultFeedName(parsed, feedURL)
+	}
+
+	metadata := feedMetadata(parsed)
+	now := time.Now().UTC()
+	return db.CreateFeed(ctx, database.CreateFeedParams{
+		ID:          uuid.New(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Name:        name,
+		Url:         feedURL,
+		UserID:      userID,
+		Title:       metadata.Title,
+		SiteUrl:     metadata.SiteUrl,
+		Description: metadata.Description,
+		Language:    metadata.Language,
+		IconUrl:     metadata.IconUrl,
+	})
+}
+
+// defaultFeedName names a feed after its channel title, or its host for the
+// rare feed without one
+func defaultFeedName(parsed *rss.RSSFeed, feedURL string) string {
+	if title := strings.TrimSpace(parsed.Channel.Title); title != "" {
+		return title
+	}
+	if u, err := url.Parse(feedURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return feedURL
+}
+
+func feedMetadata(parsed *rss.RSSFeed) database.UpdateFeedMetadataParams {
+	return database.UpdateFeedMetadataParams{
+		Title:       nullString(strings.TrimSpace(parsed.Channel.Title)),
+		SiteUrl:     nullString(parsed.Channel.Link),
+		Description: nullString(strings.TrimSpace(parsed.Channel.Description)),
+		Language:    nullString(parsed.Channel.Language),
+		IconUrl:     nullString(parsed.Channel.Icon),
+	}
+}
+
+// refreshMetadata keeps the stored channel metadata in step with the feed,
+// writing only when something actually changed
+func refreshMetadata(ctx context.Context, db *database.Queries, feed database.Feed, parsed *rss.RSSFeed) {
+	metadata := feedMetadata(parsed)
+	if metadata.Title == feed.Title && metadata.SiteUrl == feed.SiteUrl &&
+		metadata.Description == feed.Description && metadata.Language == feed.Language &&
+		metadata.IconUrl == feed.IconUrl {
+		return
+	}
+
+	metadata.ID = feed.ID
+	if err := db.UpdateFeedMetadata(ctx, metadata); err != nil {
+		log.Printf("Couldn't save metadata for feed %s: %v", feed.Name, err)
+	}
+}
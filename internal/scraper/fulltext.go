@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/LFroesch/Gator/internal/database"
+	"github.com/LFroesch/Gator/internal/readability"
+)
+
+const (
+	// fullTextBatch is how many queued posts are claimed at a time
+	fullTextBatch = 10
+	// fullTextIdle is how long the worker waits when the queue is empty
+	fullTextIdle = 30 * time.Second
+)
+
+// RunFullTextWorker extracts the articles queued by ScrapeFeed for feeds with
+// full text turned on until ctx is done. It runs apart from scraping, since
+// fetching articles at the per-host pace would outlast a feed's lease or an
+// API request. Both agg and the API server run one; they share the queue.
+func RunFullTextWorker(ctx context.Context, db *database.Queries) {
+	for ctx.Err() == nil {
+		n, err := extractFullText(ctx, db, fullTextBatch)
+		if err != nil {
+			log.Printf("Couldn't extract full text: %v", err)
+		}
+		if n == 0 || err != nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(fullTextIdle):
+			}
+		}
+	}
+}
+
+// extractFullText works through up to limit queued posts, replacing each
+// post's content with the article its link points to. A post whose
+// extraction fails keeps what the feed gave it. It returns how many jobs it
+// took.
+func extractFullText(ctx context.Context, db *database.Queries, limit int) (int, error) {
+	jobs, err := db.ClaimFullTextJobs(ctx, int32(limit))
+	if err != nil {
+		return 0, fmt.Errorf("couldn't claim full text jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		content, err := readability.Extract(ctx, job.Url)
+		if err != nil {
+			log.Printf("Couldn't extract full text from %s: %v", job.Url, err)
+			continue
+		}
+		err = db.SetPostContent(ctx, database.SetPostContentParams{
+			ID:      job.PostID,
+			Content: nullString(content),
+		})
+		if err != nil {
+			log.Printf("Couldn't save full text for %s: %v", job.Url, err)
+		}
+	}
+	return len(jobs), nil
+}
@@ -0,0 +1,66 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/LFroesch/Gator/internal/database"
+	"github.com/LFroesch/Gator/internal/rss"
+)
+
+// DisableAfterDays is how long a feed may keep failing, without a single
+// successful fetch, before the aggregator stops polling it
+const DisableAfterDays = 14
+
+// recordSuccess clears the feed's failure streak
+func recordSuccess(ctx context.Context, db *database.Queries, feed database.Feed) {
+	if err := db.RecordFeedSuccess(ctx, feed.ID); err != nil {
+		log.Printf("Couldn't record successful fetch for feed %s: %v", feed.Name, err)
+	}
+}
+
+// recordFailure stores the error on the feed and pushes its next fetch back
+// exponentially with each consecutive failure
+func recordFailure(ctx context.Context, db *database.Queries, feed database.Feed, fetchErr error) {
+	updated, err := db.RecordFeedFailure(ctx, database.RecordFeedFailureParams{
+		LastError:        sql.NullString{String: fetchErr.Error(), Valid: true},
+		DisableAfterDays: DisableAfterDays,
+		ID:               feed.ID,
+	})
+	if err != nil {
+		log.Printf("Couldn't record failed fetch for feed %s: %v", feed.Name, err)
+		updated = feed
+	}
+
+	// 410 Gone means the feed is never coming back, so don't wait out the
+	// usual grace period
+	var statusErr *rss.StatusError
+	if errors.As(fetchErr, &statusErr) && statusErr.StatusCode == http.StatusGone && !updated.DisabledAt.Valid {
+		disabled, err := db.DisableFeed(ctx, database.DisableFeedParams{
+			ID:        feed.ID,
+			LastError: sql.NullString{String: fetchErr.Error(), Valid: true},
+		})
+		if err != nil {
+			log.Printf("Couldn't disable feed %s: %v", feed.Name, err)
+		} else {
+			log.Printf("Feed %s disabled: the server says it is gone (410)", feed.Name)
+			updated = disabled
+		}
+	} else if updated.DisabledAt.Valid && !feed.DisabledAt.Valid {
+		log.Printf("Feed %s disabled after %d days without a successful fetch", feed.Name, DisableAfterDays)
+	}
+
+	interval := refreshInterval(updated, updated.FeedTtlSeconds, 0)
+	if backoff := backoffInterval(updated.ConsecutiveFailures); backoff > interval {
+		interval = backoff
+	}
+	// A host that is rate limiting us or down for maintenance may say when
+	// to come back, which can be later than our own backoff
+	if statusErr != nil && statusErr.RetryAfter > interval {
+		interval = min(statusErr.RetryAfter, maxRefreshInterval)
+	}
+	scheduleNextFetch(ctx, db, updated, updated.FeedTtlSeconds, interval)
+}
@@ -0,0 +1,43 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/LFroesch/Gator/internal/database"
+	"github.com/LFroesch/Gator/internal/rss"
+	"github.com/google/uuid"
+)
+
+// saveFetch writes one row to feed_fetches describing a poll of feed.
+// result may be nil when the request never got a response.
+func saveFetch(ctx context.Context, db *database.Queries, feed database.Feed, started time.Time, result *rss.Result, newPosts int, fetchErr error) {
+	finished := time.Now().UTC()
+
+	params := database.CreateFeedFetchParams{
+		ID:           uuid.New(),
+		CreatedAt:    finished,
+		FeedID:       feed.ID,
+		StartedAt:    started,
+		FinishedAt:   finished,
+		NewPostCount: int32(newPosts),
+		DurationMs:   int32(finished.Sub(started).Milliseconds()),
+	}
+	if result != nil {
+		params.StatusCode = sql.NullInt32{Int32: int32(result.StatusCode), Valid: true}
+		params.Bytes = result.Bytes
+		params.Truncated = result.Truncated
+		if result.Feed != nil {
+			params.ItemCount = int32(len(result.Feed.Channel.Item))
+		}
+	}
+	if fetchErr != nil {
+		params.Error = sql.NullString{String: fetchErr.Error(), Valid: true}
+	}
+
+	if err := db.CreateFeedFetch(ctx, params); err != nil {
+		log.Printf("Couldn't record fetch history for feed %s: %v", feed.Name, err)
+	}
+}
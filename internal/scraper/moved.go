@@ -0,0 +1,67 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/LFroesch/Gator/internal/database"
+)
+
+// moveFeed points a feed at the URL it permanently redirected to. Feed URLs
+// are unique, so if another feed already lives there the two are merged:
+// followers and posts move over to the existing feed and the old one is
+// deleted. Bookmarks and reads on posts the existing feed already has move to
+// its copy of the post. The merge runs in a transaction on conn, the
+// connection db uses, so a failure leaves both feeds as they were. It returns
+// the feed that lives on.
+func moveFeed(ctx context.Context, conn *sql.DB, db *database.Queries, feed database.Feed, newURL string) (database.Feed, error) {
+	existing, err := db.GetFeedByURL(ctx, newURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := db.UpdateFeedURL(ctx, database.UpdateFeedURLParams{ID: feed.ID, Url: newURL}); err != nil {
+			return feed, fmt.Errorf("couldn't update feed URL: %w", err)
+		}
+		log.Printf("Feed %s moved permanently to %s", feed.Name, newURL)
+		feed.Url = newURL
+		return feed, nil
+	}
+	if err != nil {
+		return feed, fmt.Errorf("couldn't look up feed at %s: %w", newURL, err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return feed, fmt.Errorf("couldn't start merge: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := db.WithTx(tx)
+
+	err = qtx.MoveFeedFollows(ctx, database.MoveFeedFollowsParams{ToFeedID: existing.ID, FromFeedID: feed.ID})
+	if err != nil {
+		return feed, fmt.Errorf("couldn't move follows: %w", err)
+	}
+	// Posts both feeds have stay behind and are deleted with the old feed,
+	// so what users did with them moves to the surviving copy first
+	err = qtx.MoveDuplicateBookmarks(ctx, database.MoveDuplicateBookmarksParams{FromFeedID: feed.ID, ToFeedID: existing.ID})
+	if err != nil {
+		return feed, fmt.Errorf("couldn't move bookmarks: %w", err)
+	}
+	err = qtx.MoveDuplicatePostReads(ctx, database.MoveDuplicatePostReadsParams{FromFeedID: feed.ID, ToFeedID: existing.ID})
+	if err != nil {
+		return feed, fmt.Errorf("couldn't move reads: %w", err)
+	}
+	err = qtx.MovePosts(ctx, database.MovePostsParams{ToFeedID: existing.ID, FromFeedID: feed.ID})
+	if err != nil {
+		return feed, fmt.Errorf("couldn't move posts: %w", err)
+	}
+	if err := qtx.DeleteFeed(ctx, feed.ID); err != nil {
+		return feed, fmt.Errorf("couldn't delete old feed: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return feed, fmt.Errorf("couldn't commit merge: %w", err)
+	}
+	log.Printf("Feed %s moved permanently to %s and was merged into %s", feed.Name, newURL, existing.Name)
+	return existing, nil
+}
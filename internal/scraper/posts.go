@@ -0,0 +1,180 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/LFroesch/Gator/internal/database"
+	"github.com/LFroesch/Gator/internal/rss"
+	"github.com/google/uuid"
+)
+
+// storePost inserts item as a new post, or revises the stored copy when the
+// publisher has changed it since. It reports whether a new post was created.
+func storePost(ctx context.Context, db *database.Queries, feed database.Feed, item rss.RSSItem) (bool, error) {
+	now := time.Now().UTC()
+	description := sql.NullString{String: item.Description, Valid: true}
+	sourceUpdatedAt := utc(rss.ParsePubDate(item.Updated))
+
+	// A post without a usable date is dated when we first saw it, so it
+	// still sorts sensibly, and flagged so clients can say so
+	publishedAt := utc(rss.ParsePubDate(item.PubDate))
+	estimated := !publishedAt.Valid
+	if estimated {
+		publishedAt = sql.NullTime{Time: now, Valid: true}
+	}
+	author := nullString(item.Author)
+	summary := nullString(item.SummaryHTML)
+	content := nullString(item.ContentHTML)
+
+	// Posts from before guids were tracked are keyed by their link. Move
+	// such a post over to the item's real key, so it is revised rather than
+	// stored again alongside its reads and bookmarks.
+	if item.Link != "" && item.Key() != item.Link {
+		_, err := db.RekeyLegacyPost(ctx, database.RekeyLegacyPostParams{
+			Guid:   item.Key(),
+			FeedID: feed.ID,
+			Url:    item.Link,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	postID := uuid.New()
+	inserted, err := db.CreatePost(ctx, database.CreatePostParams{
+		ID:                   postID,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+		FeedID:               feed.ID,
+		Title:                item.Title,
+		Description:          description,
+		Url:                  item.Link,
+		PublishedAt:          publishedAt,
+		Guid:                 item.Key(),
+		SourceUpdatedAt:      sourceUpdatedAt,
+		Author:               author,
+		Content:              content,
+		Summary:              summary,
+		PublishedAtEstimated: estimated,
+	})
+	if err != nil {
+		return false, err
+	}
+	if inserted > 0 {
+		if feed.FetchFullText && item.Link != "" {
+			// Extraction is slow, so it is queued for ExtractFullText
+			// rather than holding up the poll
+			err := db.EnqueueFullText(ctx, database.EnqueueFullTextParams{
+				PostID:    postID,
+				Url:       item.Link,
+				CreatedAt: now,
+			})
+			if err != nil {
+				log.Printf("Couldn't queue full text for %s: %v", item.Link, err)
+			}
+		}
+		return true, storeEnclosures(ctx, db, postID, item.Enclosures)
+	}
+
+	existing, err := db.GetPostByFeedAndGuid(ctx, database.GetPostByFeedAndGuidParams{
+		FeedID: feed.ID,
+		Guid:   item.Key(),
+	})
+	if err != nil {
+		return false, err
+	}
+	// Keep the extracted article rather than the feed's teaser
+	if feed.FetchFullText && existing.Content.Valid {
+		content = existing.Content
+	}
+	if err := revisePost(ctx, db, existing, item, description, sourceUpdatedAt, author, summary, content); err != nil {
+		return false, err
+	}
+	return false, storeEnclosures(ctx, db, existing.ID, item.Enclosures)
+}
+
+// storeEnclosures records the media attached to a post. Enclosures that have
+// since disappeared from the feed are kept, since a podcast episode's audio
+// doesn't stop existing because the publisher trimmed the item.
+func storeEnclosures(ctx context.Context, db *database.Queries, postID uuid.UUID, enclosures []rss.Enclosure) error {
+	now := time.Now().UTC()
+	for _, enclosure := range enclosures {
+		err := db.UpsertPostEnclosure(ctx, database.UpsertPostEnclosureParams{
+			ID:              uuid.New(),
+			CreatedAt:       now,
+			UpdatedAt:       now,
+			PostID:          postID,
+			Url:             enclosure.URL,
+			MimeType:        nullString(enclosure.MimeType),
+			Length:          sql.NullInt64{Int64: enclosure.Length, Valid: enclosure.Length > 0},
+			DurationSeconds: sql.NullInt32{Int32: int32(enclosure.DurationSeconds), Valid: enclosure.DurationSeconds > 0},
+			ThumbnailUrl:    nullString(enclosure.ThumbnailURL),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revisePost brings a stored post up to date with the feed. A new title or a
+// newer <updated> timestamp counts as an edit: the old copy is kept in
+// post_revisions and edited_at is set. Other changes, like a Hacker News
+// points count in the description, are refreshed in place.
+func revisePost(ctx context.Context, db *database.Queries, existing database.Post, item rss.RSSItem, description sql.NullString, sourceUpdatedAt sql.NullTime, author, summary, content sql.NullString) error {
+	titleChanged := existing.Title != item.Title
+	republished := existing.SourceUpdatedAt.Valid && sourceUpdatedAt.Valid &&
+		sourceUpdatedAt.Time.After(existing.SourceUpdatedAt.Time)
+	changed := titleChanged || republished ||
+		existing.Url != item.Link ||
+		existing.Description != description ||
+		existing.Author != author ||
+		existing.Summary != summary ||
+		existing.Content != content ||
+		existing.SourceUpdatedAt.Valid != sourceUpdatedAt.Valid
+	if !changed {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	editedAt := existing.EditedAt
+	if titleChanged || republished {
+		err := db.CreatePostRevision(ctx, database.CreatePostRevisionParams{
+			ID:              uuid.New(),
+			CreatedAt:       now,
+			PostID:          existing.ID,
+			Title:           existing.Title,
+			Description:     existing.Description,
+			SourceUpdatedAt: existing.SourceUpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		editedAt = sql.NullTime{Time: now, Valid: true}
+	}
+
+	return db.UpdatePostContent(ctx, database.UpdatePostContentParams{
+		ID:              existing.ID,
+		Title:           item.Title,
+		Url:             item.Link,
+		Description:     description,
+		SourceUpdatedAt: sourceUpdatedAt,
+		EditedAt:        editedAt,
+		UpdatedAt:       now,
+		Author:          author,
+		Content:         content,
+		Summary:         summary,
+	})
+}
+
+// utc normalizes a timestamp before it goes into a TIMESTAMP column, which
+// would otherwise silently drop the feed's UTC offset
+func utc(t sql.NullTime) sql.NullTime {
+	if t.Valid {
+		t.Time = t.Time.UTC()
+	}
+	return t
+}
@@ -0,0 +1,81 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/LFroesch/Gator/internal/database"
+)
+
+const (
+	// defaultRefreshInterval is used when neither the feed, the server nor
+	// the user say how often to poll
+	defaultRefreshInterval = time.Hour
+	// Publisher hints are clamped so a bogus <ttl> can't make us hammer a
+	// host or forget a feed for weeks
+	minRefreshInterval = 5 * time.Minute
+	maxRefreshInterval = 24 * time.Hour
+)
+
+// refreshInterval decides how long to wait before polling feed again. A user
+// override always wins; otherwise the longer of the feed's own hint and the
+// HTTP freshness lifetime is used.
+func refreshInterval(feed database.Feed, feedTTL sql.NullInt32, httpMaxAge time.Duration) time.Duration {
+	if feed.FetchIntervalSeconds.Valid && feed.FetchIntervalSeconds.Int32 > 0 {
+		return time.Duration(feed.FetchIntervalSeconds.Int32) * time.Second
+	}
+
+	interval := httpMaxAge
+	if feedTTL.Valid {
+		if ttl := time.Duration(feedTTL.Int32) * time.Second; ttl > interval {
+			interval = ttl
+		}
+	}
+	if interval == 0 {
+		return defaultRefreshInterval
+	}
+	if interval < minRefreshInterval {
+		return minRefreshInterval
+	}
+	if interval > maxRefreshInterval {
+		return maxRefreshInterval
+	}
+	return interval
+}
+
+// backoffInterval doubles the wait with every consecutive failure, starting
+// at minRefreshInterval and capped at maxRefreshInterval
+func backoffInterval(failures int32) time.Duration {
+	interval := minRefreshInterval
+	for i := int32(1); i < failures && interval < maxRefreshInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxRefreshInterval {
+		return maxRefreshInterval
+	}
+	return interval
+}
+
+// scheduleNextFetch stores the feed's advertised interval and makes the feed
+// due again after interval
+func scheduleNextFetch(ctx context.Context, db *database.Queries, feed database.Feed, feedTTL sql.NullInt32, interval time.Duration) {
+	next := time.Now().UTC().Add(interval)
+	err := db.ScheduleNextFetch(ctx, database.ScheduleNextFetchParams{
+		ID:             feed.ID,
+		FeedTtlSeconds: feedTTL,
+		NextFetchAt:    sql.NullTime{Time: next, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Couldn't schedule next fetch for feed %s: %v", feed.Name, err)
+	}
+}
+
+// nullSeconds converts a duration into a nullable whole-second count
+func nullSeconds(d time.Duration) sql.NullInt32 {
+	if d <= 0 {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(d.Seconds()), Valid: true}
+}
@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/LFroesch/Gator/internal/database"
+	"github.com/LFroesch/Gator/internal/rss"
+)
+
+// ScrapeFeed fetches a feed and stores any posts we haven't seen yet,
+// returning how many were added. It is shared by the agg command and the API
+// server so both store posts the same way. conn is the connection db runs on,
+// for the steps that need a transaction.
+func ScrapeFeed(ctx context.Context, conn *sql.DB, db *database.Queries, feed database.Feed) (newPosts int, err error) {
+	started := time.Now().UTC()
+	var result *rss.Result
+	defer func() {
+		saveFetch(ctx, db, feed, started, result, newPosts, err)
+	}()
+
+	result, err = rss.Fetch(ctx, feed.Url, rss.Validators{
+		ETag:         feed.Etag.String,
+		LastModified: feed.LastModified.String,
+	})
+	if err != nil {
+		recordFailure(ctx, db, feed, err)
+		return 0, fmt.Errorf("couldn't fetch feed %s: %w", feed.Name, err)
+	}
+	recordSuccess(ctx, db, feed)
+
+	if result.MovedTo != "" && result.MovedTo != feed.Url {
+		if moved, err := moveFeed(ctx, conn, db, feed, result.MovedTo); err != nil {
+			log.Printf("Couldn't move feed %s to %s: %v", feed.Name, result.MovedTo, err)
+		} else {
+			feed = moved
+		}
+	}
+
+	// Nothing changed since the last fetch, so there is nothing to parse and
+	// the feed's previously advertised interval still applies
+	if result.NotModified {
+		scheduleNextFetch(ctx, db, feed, feed.FeedTtlSeconds, refreshInterval(feed, feed.FeedTtlSeconds, result.MaxAge))
+		return 0, nil
+	}
+	feedTTL := nullSeconds(result.Feed.RefreshInterval())
+	scheduleNextFetch(ctx, db, feed, feedTTL, refreshInterval(feed, feedTTL, result.MaxAge))
+
+	refreshMetadata(ctx, db, feed, result.Feed)
+	if result.Truncated {
+		log.Printf("Feed %s was cut off at the size limit, only its first %d items were read", feed.Name, len(result.Feed.Channel.Item))
+	}
+
+	storeFailed := false
+	for _, item := range result.Feed.Channel.Item {
+		created, err := storePost(ctx, db, feed, item)
+		if err != nil {
+			log.Printf("Couldn't store post: %v", err)
+			storeFailed = true
+			continue
+		}
+		if created {
+			newPosts++
+		}
+	}
+
+	// The validators are only saved once every item is stored. Otherwise the
+	// next fetch would get a 304 and never retry the items that failed.
+	if storeFailed {
+		return newPosts, nil
+	}
+	err = db.UpdateFeedCacheHeaders(ctx, database.UpdateFeedCacheHeadersParams{
+		ID:           feed.ID,
+		Etag:         nullString(result.Validators.ETag),
+		LastModified: nullString(result.Validators.LastModified),
+	})
+	if err != nil {
+		log.Printf("Couldn't save cache headers for feed %s: %v", feed.Name, err)
+	}
+
+	return newPosts, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
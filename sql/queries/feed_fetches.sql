@@ -0,0 +1,9 @@
+-- name: CreateFeedFetch :exec
+INSERT INTO feed_fetches (id, created_at, feed_id, started_at, finished_at, status_code, bytes, item_count, new_post_count, duration_ms, error, truncated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: GetFeedFetches :many
+SELECT * FROM feed_fetches
+WHERE feed_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
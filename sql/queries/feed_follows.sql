@@ -24,4 +24,12 @@ WHERE feed_follows.user_id = $1;
 
 -- name: DeleteFeedFollowByUserAndFeed :exec
 DELETE FROM feed_follows
-WHERE user_id = $1 AND feed_id = $2;
\ No newline at end of file
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: MoveFeedFollows :exec
+-- Moves follows from one feed to another, leaving behind those of users who
+-- already follow the target so they get deleted along with the old feed
+UPDATE feed_follows
+SET feed_id = sqlc.arg(to_feed_id), updated_at = NOW()
+WHERE feed_id = sqlc.arg(from_feed_id)
+  AND user_id NOT IN (SELECT user_id FROM feed_follows WHERE feed_id = sqlc.arg(to_feed_id));
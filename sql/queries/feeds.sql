@@ -1,17 +1,22 @@
 -- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, title, site_url, description, language, icon_url)
 VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
 )
 RETURNING *;
 
 -- name: GetAllFeeds :many
-SELECT feeds.id, feeds.name, feeds.url, feeds.user_id, users.name as username
+SELECT feeds.id, feeds.name, feeds.url, feeds.user_id, feeds.description, feeds.site_url, feeds.icon_url, users.name as username
 FROM feeds
 JOIN users ON feeds.user_id = users.id;
 
@@ -26,6 +31,30 @@ SELECT * FROM feeds
 ORDER BY last_fetched_at NULLS FIRST
 LIMIT 1;
 
+-- name: ClaimNextFeedToFetch :one
+-- Leases the most overdue unleased feed to lease_owner. SKIP LOCKED keeps
+-- concurrent workers and other agg processes from claiming the same feed, and
+-- a lease left behind by a crashed worker is reclaimable once it expires
+UPDATE feeds
+SET lease_owner = sqlc.arg(lease_owner),
+    lease_expires_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::int),
+    updated_at = NOW()
+WHERE id = (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL
+      AND (lease_expires_at IS NULL OR lease_expires_at < NOW())
+      AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    ORDER BY next_fetch_at NULLS FIRST, last_fetched_at NULLS FIRST
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), lease_owner = NULL, lease_expires_at = NULL, updated_at = NOW()
+WHERE id = $1 AND lease_owner = $2;
+
 -- name: UpdateFeed :one
 UPDATE feeds 
 SET name = $2, url = $3, updated_at = $4
@@ -36,4 +65,89 @@ RETURNING *;
 DELETE FROM feeds WHERE id = $1;
 
 -- name: GetFeedByID :one
-SELECT * FROM feeds WHERE id = $1;
\ No newline at end of file
+SELECT * FROM feeds WHERE id = $1;
+
+-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds
+SET etag = $2, last_modified = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET title = $2, site_url = $3, description = $4, language = $5, icon_url = $6, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ScheduleNextFetch :exec
+UPDATE feeds
+SET feed_ttl_seconds = $2, next_fetch_at = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedFetchInterval :one
+-- Overrides the refresh interval (NULL returns to automatic) and makes the
+-- feed due immediately so the new interval takes effect on the next round
+UPDATE feeds
+SET fetch_interval_seconds = $2, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET last_success_at = NOW(), consecutive_failures = 0, last_error = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RecordFeedFailure :one
+-- Counts a failed fetch and disables the feed once it has gone
+-- disable_after_days without a single successful fetch. A feed that has
+-- never been fetched successfully counts from when it was added.
+UPDATE feeds
+SET consecutive_failures = consecutive_failures + 1,
+    last_error = sqlc.arg(last_error),
+    disabled_at = CASE
+        WHEN COALESCE(last_success_at, created_at) < NOW() - make_interval(days => sqlc.arg(disable_after_days)::int)
+        THEN NOW()
+        ELSE disabled_at
+    END,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetBrokenFeeds :many
+SELECT * FROM feeds
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY disabled_at NULLS LAST, consecutive_failures DESC;
+
+-- name: EnableFeed :one
+UPDATE feeds
+SET disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetDownloadFeeds :many
+-- Feeds with enclosure downloads turned on, for agg's download worker
+SELECT * FROM feeds
+WHERE download_enclosures AND disabled_at IS NULL
+ORDER BY last_fetched_at DESC NULLS LAST;
+
+-- name: SetFeedDownloads :one
+UPDATE feeds
+SET download_enclosures = $2, download_retention = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetFeedFullText :one
+UPDATE feeds
+SET fetch_full_text = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DisableFeed :one
+-- Disables a feed right away, for servers that say it is gone for good
+UPDATE feeds
+SET disabled_at = NOW(), last_error = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateFeedURL :exec
+UPDATE feeds
+SET url = $2, updated_at = NOW()
+WHERE id = $1;
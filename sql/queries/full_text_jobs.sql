@@ -0,0 +1,16 @@
+-- name: EnqueueFullText :exec
+INSERT INTO full_text_jobs (post_id, url, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (post_id) DO NOTHING;
+
+-- name: ClaimFullTextJobs :many
+-- Takes the oldest jobs off the queue. SKIP LOCKED lets several aggregators
+-- and the API server work through it without picking the same posts.
+DELETE FROM full_text_jobs
+WHERE post_id IN (
+    SELECT post_id FROM full_text_jobs
+    ORDER BY created_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING post_id, url;
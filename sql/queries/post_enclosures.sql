@@ -0,0 +1,51 @@
+-- name: UpsertPostEnclosure :exec
+-- Stores an enclosure for a post, refreshing its details only when the feed
+-- reports something different from what we already have
+INSERT INTO post_enclosures (id, created_at, updated_at, post_id, url, mime_type, length, duration_seconds, thumbnail_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (post_id, url) DO UPDATE
+SET mime_type = EXCLUDED.mime_type,
+    length = EXCLUDED.length,
+    duration_seconds = EXCLUDED.duration_seconds,
+    thumbnail_url = EXCLUDED.thumbnail_url,
+    updated_at = EXCLUDED.updated_at
+WHERE (post_enclosures.mime_type, post_enclosures.length, post_enclosures.duration_seconds, post_enclosures.thumbnail_url)
+    IS DISTINCT FROM (EXCLUDED.mime_type, EXCLUDED.length, EXCLUDED.duration_seconds, EXCLUDED.thumbnail_url);
+
+-- name: GetPostEnclosures :many
+SELECT * FROM post_enclosures
+WHERE post_id = $1
+ORDER BY created_at, url;
+
+-- name: GetRecentFeedEnclosures :many
+-- The audio and video enclosures of a feed's newest posts, which are the
+-- ones agg keeps downloaded
+SELECT post_enclosures.*, posts.title AS post_title FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id
+WHERE posts.feed_id = $1
+  AND (post_enclosures.mime_type LIKE 'audio/%' OR post_enclosures.mime_type LIKE 'video/%')
+ORDER BY posts.published_at DESC NULLS LAST, posts.created_at DESC
+LIMIT $2;
+
+-- name: GetExpiredEnclosureDownloads :many
+-- Downloaded enclosures of a feed beyond its retention count, oldest last
+SELECT post_enclosures.* FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id
+WHERE posts.feed_id = $1 AND post_enclosures.local_path IS NOT NULL
+ORDER BY posts.published_at DESC NULLS LAST, posts.created_at DESC
+OFFSET $2;
+
+-- name: SetEnclosureDownloaded :exec
+UPDATE post_enclosures
+SET local_path = $2, downloaded_at = $3, download_error = NULL, updated_at = $3
+WHERE id = $1;
+
+-- name: SetEnclosureDownloadError :exec
+UPDATE post_enclosures
+SET download_error = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ClearEnclosureDownload :exec
+UPDATE post_enclosures
+SET local_path = NULL, downloaded_at = NULL, updated_at = NOW()
+WHERE id = $1;
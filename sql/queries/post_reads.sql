@@ -22,3 +22,15 @@ SELECT EXISTS(
     SELECT 1 FROM post_reads
     WHERE user_id = $1 AND post_id = $2
 ) as is_read;
+
+-- name: MoveDuplicatePostReads :exec
+-- Repoints post_reads on posts of one feed at the post with the same guid in
+-- another, skipping users who already have one there
+UPDATE post_reads
+SET post_id = target.id, updated_at = NOW()
+FROM posts source, posts target
+WHERE post_reads.post_id = source.id
+  AND source.feed_id = sqlc.arg(from_feed_id)
+  AND target.feed_id = sqlc.arg(to_feed_id)
+  AND target.guid = source.guid
+  AND NOT EXISTS (SELECT 1 FROM post_reads existing WHERE existing.user_id = post_reads.user_id AND existing.post_id = target.id);
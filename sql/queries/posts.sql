@@ -1,7 +1,23 @@
--- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING *;
+-- name: CreatePost :execrows
+-- Inserts a post unless the feed already has one with the same guid; the
+-- affected row count tells the caller whether the post was new
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid, source_updated_at, author, content, summary, published_at_estimated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (feed_id, guid) DO NOTHING;
+
+-- name: GetPostByFeedAndGuid :one
+SELECT * FROM posts
+WHERE feed_id = $1 AND guid = $2;
+
+-- name: UpdatePostContent :exec
+UPDATE posts
+SET title = $2, url = $3, description = $4, source_updated_at = $5, edited_at = $6, updated_at = $7, author = $8, content = $9, summary = $10
+WHERE id = $1;
+
+-- name: GetPostByID :one
+SELECT posts.*, feeds.name AS feed_name FROM posts
+JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.id = $1;
 
 -- name: GetPostsForUser :many
 SELECT posts.*, feeds.name AS feed_name FROM posts
@@ -20,4 +36,26 @@ ORDER BY posts.published_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: DeleteAllPosts :exec
-DELETE FROM posts;
\ No newline at end of file
+DELETE FROM posts;
+
+-- name: MovePosts :exec
+-- Moves posts from one feed to another, skipping any the target already has
+UPDATE posts
+SET feed_id = sqlc.arg(to_feed_id), updated_at = NOW()
+WHERE feed_id = sqlc.arg(from_feed_id)
+  AND guid NOT IN (SELECT guid FROM posts WHERE feed_id = sqlc.arg(to_feed_id));
+
+-- name: SetPostContent :exec
+UPDATE posts
+SET content = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RekeyLegacyPost :execrows
+-- Gives a post that migration 013 keyed by its link the item's real guid,
+-- unless the feed already has a post under that guid
+UPDATE posts
+SET guid = sqlc.arg(guid)
+WHERE feed_id = sqlc.arg(feed_id)
+  AND url = sqlc.arg(url)
+  AND guid = url
+  AND NOT EXISTS (SELECT 1 FROM posts WHERE feed_id = sqlc.arg(feed_id) AND guid = sqlc.arg(guid));
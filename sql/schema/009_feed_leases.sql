@@ -0,0 +1,8 @@
+-- +goose Up
+-- A worker holds a feed until lease_expires_at; expired leases are reclaimable
+ALTER TABLE feeds ADD COLUMN lease_owner TEXT;
+ALTER TABLE feeds ADD COLUMN lease_expires_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN lease_expires_at;
+ALTER TABLE feeds DROP COLUMN lease_owner;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- next_fetch_at is when the aggregator should poll the feed again.
+-- feed_ttl_seconds remembers the interval the feed advertises (<ttl>, sy:*)
+-- and fetch_interval_seconds is an optional user override of everything else.
+ALTER TABLE feeds ADD COLUMN next_fetch_at TIMESTAMP;
+ALTER TABLE feeds ADD COLUMN feed_ttl_seconds INTEGER;
+ALTER TABLE feeds ADD COLUMN fetch_interval_seconds INTEGER;
+CREATE INDEX feeds_next_fetch_at_idx ON feeds (next_fetch_at);
+
+-- +goose Down
+DROP INDEX feeds_next_fetch_at_idx;
+ALTER TABLE feeds DROP COLUMN fetch_interval_seconds;
+ALTER TABLE feeds DROP COLUMN feed_ttl_seconds;
+ALTER TABLE feeds DROP COLUMN next_fetch_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Posts are now unique per feed by the item's <guid>/<id>, so two feeds can
+-- carry the same article. Existing rows are keyed the same way the scraper
+-- keys items without a guid: by link, or by a hash when there is no link.
+-- Items that do have a guid are moved over to it by the scraper the first
+-- time it sees them again (see RekeyLegacyPost).
+ALTER TABLE posts ADD COLUMN guid TEXT;
+UPDATE posts SET guid = url WHERE url <> '';
+UPDATE posts SET guid = 'md5:' || md5(title || E'\n' || COALESCE(description, '')) WHERE url = '';
+ALTER TABLE posts ALTER COLUMN guid SET NOT NULL;
+ALTER TABLE posts DROP CONSTRAINT posts_url_key;
+ALTER TABLE posts ADD CONSTRAINT posts_feed_id_guid_key UNIQUE (feed_id, guid);
+
+-- +goose Down
+ALTER TABLE posts DROP CONSTRAINT posts_feed_id_guid_key;
+ALTER TABLE posts ADD CONSTRAINT posts_url_key UNIQUE (url);
+ALTER TABLE posts DROP COLUMN guid;
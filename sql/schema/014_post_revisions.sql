@@ -0,0 +1,19 @@
+-- +goose Up
+-- source_updated_at is the item's own <updated> time; edited_at is set when
+-- the publisher revised a post after we first stored it
+ALTER TABLE posts ADD COLUMN source_updated_at TIMESTAMP;
+ALTER TABLE posts ADD COLUMN edited_at TIMESTAMP;
+
+CREATE TABLE post_revisions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT,
+    source_updated_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE post_revisions;
+ALTER TABLE posts DROP COLUMN edited_at;
+ALTER TABLE posts DROP COLUMN source_updated_at;
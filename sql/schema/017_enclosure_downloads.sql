@@ -0,0 +1,17 @@
+-- +goose Up
+-- Feeds can opt in to having agg save their enclosures to disk; only the
+-- newest download_retention episodes are kept (NULL keeps everything)
+ALTER TABLE feeds ADD COLUMN download_enclosures BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE feeds ADD COLUMN download_retention INTEGER;
+
+ALTER TABLE post_enclosures ADD COLUMN local_path TEXT;
+ALTER TABLE post_enclosures ADD COLUMN downloaded_at TIMESTAMP;
+ALTER TABLE post_enclosures ADD COLUMN download_error TEXT;
+
+-- +goose Down
+ALTER TABLE post_enclosures DROP COLUMN download_error;
+ALTER TABLE post_enclosures DROP COLUMN downloaded_at;
+ALTER TABLE post_enclosures DROP COLUMN local_path;
+
+ALTER TABLE feeds DROP COLUMN download_retention;
+ALTER TABLE feeds DROP COLUMN download_enclosures;
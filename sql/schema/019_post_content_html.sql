@@ -0,0 +1,7 @@
+-- +goose Up
+-- The item body with safe formatting kept, for clients that render HTML;
+-- description stays plain text
+ALTER TABLE posts ADD COLUMN content_html TEXT;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN content_html;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Keep the item's teaser and its full body apart instead of whichever the
+-- feed happened to provide; both hold sanitized HTML
+ALTER TABLE posts RENAME COLUMN content_html TO content;
+ALTER TABLE posts ADD COLUMN summary TEXT;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN summary;
+ALTER TABLE posts RENAME COLUMN content TO content_html;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Feeds that only carry teasers can opt in to having the linked article
+-- downloaded and its main text stored as each new post's content
+ALTER TABLE feeds ADD COLUMN fetch_full_text BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN fetch_full_text;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Posts whose feed gives no usable date are dated when we first saw them
+-- instead of NULL, which sorted them unpredictably, and flagged as such
+ALTER TABLE posts ADD COLUMN published_at_estimated BOOLEAN NOT NULL DEFAULT false;
+UPDATE posts SET published_at = created_at, published_at_estimated = true WHERE published_at IS NULL;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN published_at_estimated;
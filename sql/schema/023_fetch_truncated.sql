@@ -0,0 +1,6 @@
+-- +goose Up
+-- Set when the feed document was cut off at the configured byte or item limit
+ALTER TABLE feed_fetches ADD COLUMN truncated BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE feed_fetches DROP COLUMN truncated;
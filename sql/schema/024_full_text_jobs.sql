@@ -0,0 +1,12 @@
+-- +goose Up
+-- Posts waiting for their linked article to be extracted. Extraction is slow
+-- and paced per host, so it runs apart from polling, outside feed leases and
+-- API requests.
+CREATE TABLE full_text_jobs (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE full_text_jobs;